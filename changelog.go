@@ -16,15 +16,16 @@ package changelog
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
-	"net/url"
+	"net/http"
 	"os"
-	"path"
 	"sort"
+	"strconv"
 	"strings"
-	"sync"
 	"text/template"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 	log "github.com/sirupsen/logrus"
@@ -34,9 +35,22 @@ import (
 	"github.com/jimschubert/changelog/service"
 )
 
-const emptyTree = "master~1"
-const defaultEnd = "master"
-const defaultTemplate = `{{define "PullTemplate"}} ({{if .IsPull -}}
+var (
+	// ErrMissingToken is returned when the GitHub API must be queried but no token is available
+	ErrMissingToken = errors.New("missing API token")
+
+	// ErrRefNotFound is matched by errors for a 'from' or 'to' revision which the store can't resolve
+	ErrRefNotFound = service.ErrRefNotFound
+
+	// ErrRateLimited is matched by errors for API requests refused until the rate limit resets
+	ErrRateLimited = service.ErrRateLimited
+
+	// ErrInvalidPattern is matched by errors for exclude or grouping patterns which aren't valid regular expressions
+	ErrInvalidPattern = model.ErrInvalidPattern
+)
+
+// DefaultTemplate renders a markdown section for the release, grouping items when groupings are configured
+const DefaultTemplate = `{{define "PullTemplate"}} ({{if .IsPull -}}
 {{if .PullURL}}[contributed]({{.PullURL}}){{else}}contributed{{end}} by {{end}}{{if .AuthorURL -}}
 [{{.Author}}]({{.AuthorURL}}){{else}}{{.Author}}{{end -}})
 {{- end -}}
@@ -64,128 +78,606 @@ const defaultTemplate = `{{define "PullTemplate"}} ({{if .IsPull -}}
 {{- else}}
 {{template "FlatTemplate" . -}}
 {{end}}
-<em>For more details, see <a href="{{.CompareURL}}">{{.PreviousVersion}}..{{.Version}}</a></em>
-{{end -}}
+{{if .PreviousVersion}}<em>For more details, see <a href="{{.CompareURL}}">{{.PreviousVersion}}..{{.Version}}</a></em>
+{{end}}{{end -}}
 {{template "DefaultTemplate" . -}}
 `
 
+// DefaultHistoryTemplate renders a markdown document of every release, each via the "Release" template
+const DefaultHistoryTemplate = `# Changelog
+{{range .Releases}}
+{{template "Release" .}}
+{{- end -}}
+`
+
 // Changelog holds the information required to define the bounds for the changelog
 type Changelog struct {
 	*model.Config
 	From string
 	To   string
+
+	// HTTPClient is the base client for API requests (e.g. to route through a proxy), beneath authentication, caching and retries.
+	// Defaults to a client using http.DefaultTransport.
+	HTTPClient *http.Client
+
+	// Store queries commits in place of the store selected by the configured provider. The config is applied via WithConfig.
+	Store service.Store
+
+	// TokenSource authenticates API requests in place of the provider's environment variable (e.g. GITHUB_TOKEN)
+	TokenSource oauth2.TokenSource
+
+	// Now is the time source for cache expiry and rate limit resets. Defaults to time.Now.
+	Now func() time.Time
 }
 
 // Generate will format a changelog, writing to the supplied writer
 func (c *Changelog) Generate(writer io.Writer) error {
-	ctx := context.Background()
-	token, found := os.LookupEnv("GITHUB_TOKEN")
-	if !found {
-		log.Fatal("Environment variable GITHUB_TOKEN not found.")
+	return c.GenerateContext(context.Background(), writer)
+}
+
+// GenerateContext is Generate, cancelling any outstanding work when ctx is done
+func (c *Changelog) GenerateContext(ctx context.Context, writer io.Writer) error {
+	data, err := c.Collect(ctx)
+	if err != nil {
+		return err
 	}
+	return Render(writer, data, c.Template())
+}
 
-	if len(c.From) == 0 {
-		c.From = emptyTree
+// GenerateHistory will format a changelog of every release, writing to the supplied writer
+func (c *Changelog) GenerateHistory(ctx context.Context, writer io.Writer) error {
+	history, err := c.CollectHistory(ctx)
+	if err != nil {
+		return err
+	}
+	return RenderHistory(writer, history, c.HistoryTemplate(), c.Template())
+}
+
+// Collect queries the configured store for changes between From and To, returning them sorted and grouped for rendering.
+// Commits which fail to process are omitted with a warning, unless the config is strict, in which case collection fails.
+// Collection is bounded by ctx and by the configured timeout, whichever ends first.
+// When To isn't defined, it's the repository's default branch. When From isn't defined, it's detected as the highest
+// versioned release tag preceding To. When the template references NextVersion, it names the release the items would make.
+func (c *Changelog) Collect(ctx context.Context) (*model.TemplateData, error) {
+	// naming the release may list every tag, so it's skipped unless rendered
+	return c.collect(ctx, strings.Contains(c.Template(), "NextVersion"))
+}
+
+// NextVersion collects the changes between From and To (defaulted as by Collect), returning the name of the release they'd
+// make. It's empty when there are no changes.
+func (c *Changelog) NextVersion(ctx context.Context) (string, error) {
+	data, err := c.collect(ctx, true)
+	if err != nil {
+		return "", err
+	}
+	return data.NextVersion, nil
+}
+
+// collect is Collect, naming the next version when nextVersion is set
+func (c *Changelog) collect(ctx context.Context, nextVersion bool) (*model.TemplateData, error) {
+	ctx, cancel, target, err := c.prepare(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer cancel()
+
 	if len(c.To) == 0 {
-		c.To = defaultEnd
+		c.To, err = c.defaultBranch(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if len(c.From) == 0 {
+		c.From, err = c.previousRelease(ctx, target)
+		if err != nil {
+			return nil, err
+		}
 	}
 
-	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
-	tc := oauth2.NewClient(ctx, ts)
+	all, err := c.changes(ctx, target, c.From, c.To)
+	if err != nil {
+		return nil, err
+	}
+	data := c.templateData(all)
+	describe := c.refDescriber(ctx, target)
+	data.Ref, data.PreviousRef = describe(c.To), describe(c.From)
+	if nextVersion {
+		data.NextVersion = c.nextVersion(ctx, target, c.From, c.To, data.Items)
+	}
+	return data, nil
+}
 
-	var client *github.Client
-	if c.Config.Enterprise != nil && *c.Config.Enterprise != "" {
-		cl, e := github.NewEnterpriseClient(*c.Config.Enterprise, *c.Config.Enterprise, tc)
-		if e != nil {
-			return e
+// CollectHistory collects a release for each consecutive pair of release tags in semantic version order, beginning with
+// the newest release. The oldest release spans from the repository root, so it has no PreviousVersion. When the config enables
+// unreleased changes, those after the newest tag (up to To, otherwise the default branch) are collected as an "Unreleased" release,
+// whose NextVersion names the release they'd make.
+func (c *Changelog) CollectHistory(ctx context.Context) (*model.History, error) {
+	ctx, cancel, target, err := c.prepare(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer cancel()
+
+	source, ok := target.(service.TagSource)
+	if !ok {
+		return nil, errors.New("history requires a store which lists tags, such as the GitHub API or a local repository")
+	}
+	tags, err := source.Tags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := service.ReleaseTags(tags, c.Config)
+	if err != nil {
+		return nil, err
+	}
+	log.WithFields(log.Fields{"releases": len(releases)}).Debug("Collecting history.")
+
+	// each tag bounds two releases, so it's described once for both
+	describe := c.refDescriber(ctx, target)
+	history := &model.History{Releases: make([]*model.TemplateData, 0, len(releases))}
+	if c.Config.GetUnreleased() && len(releases) > 0 {
+		to := c.To
+		if len(to) == 0 {
+			if to, err = c.defaultBranch(ctx, target); err != nil {
+				return nil, err
+			}
+		}
+		data, err := c.collectRelease(ctx, target, releases[0].Name, to, describe)
+		if err != nil {
+			return nil, err
+		}
+		if len(data.Items) > 0 {
+			data.Version = "Unreleased"
+			data.NextVersion = c.nextVersion(ctx, target, releases[0].Name, to, data.Items)
+			history.Releases = append(history.Releases, data)
 		}
-		client = cl
-	} else {
-		client = github.NewClient(tc)
 	}
 
-	doneChan := make(chan struct{})
-	errorChan := make(chan error)
-	ciChan := make(chan *model.ChangeItem)
+	for i := 0; i+1 < len(releases); i++ {
+		data, err := c.collectRelease(ctx, target, releases[i+1].Name, releases[i].Name, describe)
+		if err != nil {
+			return nil, err
+		}
+		history.Releases = append(history.Releases, data)
+	}
 
-	wg := sync.WaitGroup{}
+	if len(releases) > 0 {
+		data, err := c.collectRelease(ctx, target, "", releases[len(releases)-1].Name, describe)
+		if err != nil {
+			return nil, err
+		}
+		history.Releases = append(history.Releases, data)
+	}
+	return history, nil
+}
 
-	var target service.Store
-	if c.Config.GetPreferLocal() {
-		target = service.NewLocalGitService().WithClient(client).WithConfig(c.Config)
-	} else {
-		target = service.NewGitHubService().WithClient(client).WithConfig(c.Config)
+// collectRelease collects the changes between from and to as a release of the history
+func (c *Changelog) collectRelease(ctx context.Context, target service.Store, from string, to string, describe func(revision string) *model.RefInfo) (*model.TemplateData, error) {
+	log.WithFields(log.Fields{"from": from, "to": to}).Debug("Collecting release.")
+	all, err := c.changes(ctx, target, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	release := *c
+	release.From, release.To = from, to
+	data := release.templateData(all)
+	data.Ref, data.PreviousRef = describe(to), describe(from)
+	return data, nil
+}
+
+// refDescriber creates a func describing revisions via target, querying each revision at most once. Revisions which can't
+// be described (including all revisions, when target doesn't implement service.RefSource) and the empty revision bounding
+// a release at the repository root are nil.
+func (c *Changelog) refDescriber(ctx context.Context, target service.Store) func(revision string) *model.RefInfo {
+	source, ok := target.(service.RefSource)
+	refs := make(map[string]*model.RefInfo)
+	return func(revision string) *model.RefInfo {
+		if !ok || revision == "" {
+			return nil
+		}
+		if ref, found := refs[revision]; found {
+			return ref
+		}
+
+		ref, err := source.Ref(ctx, revision)
+		if err != nil {
+			log.WithFields(log.Fields{"revision": revision, "error": err}).Warn("Unable to describe revision.")
+		}
+		refs[revision] = ref
+		return ref
+	}
+}
+
+// prepare validates the config (including its patterns) and selects the store, bounding ctx by the configured timeout. The returned
+// context.CancelFunc must be called once collection completes.
+func (c *Changelog) prepare(ctx context.Context) (context.Context, context.CancelFunc, service.Store, error) {
+	if _, err := c.Config.GetRequestTimeout(); err != nil {
+		return nil, nil, nil, err
 	}
-	err := target.Process(&ctx, &wg, ciChan, c.From, c.To)
+	if err := c.Config.ValidatePatterns(); err != nil {
+		return nil, nil, nil, err
+	}
+	timeout, err := c.Config.GetTimeout()
 	if err != nil {
-		return err
+		return nil, nil, nil, err
+	}
+	cancel := context.CancelFunc(func() {})
+	if timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+	}
+
+	if _, err := c.Config.URLBuilder(); err != nil {
+		if c.Config.GetStrict() {
+			cancel()
+			return nil, nil, nil, err
+		}
+		log.WithFields(log.Fields{"error": err}).Warn("Invalid urls config, links will be omitted.")
 	}
 
-	go wait(doneChan, &wg)
+	target, err := c.newStore(ctx)
+	if err != nil {
+		cancel()
+		return nil, nil, nil, err
+	}
+	if c.Store == nil {
+		// stores created here request through retryTransport, which bounds each attempt by the request timeout
+		ctx = service.WithAttemptTimeouts(ctx)
+	}
+	return ctx, cancel, target, nil
+}
 
+// changes queries target for the changes between from and to, omitting (with a warning) commits which fail to process
+// unless the config is strict. An exhausted rate limit or an ended run fails regardless, as it affects every remaining
+// commit and omitting them all would silently produce incomplete release notes.
+func (c *Changelog) changes(ctx context.Context, target service.Store, from string, to string) ([]model.ChangeItem, error) {
+	strict := c.Config.GetStrict()
 	all := make([]model.ChangeItem, 0)
-	for {
-		select {
-		case e := <-errorChan:
-			return e
-		case ci := <-ciChan:
-			if ci != nil {
-				all = append(all, *ci)
+	omitted := 0
+	for ci, err := range target.Changes(ctx, from, to) {
+		if err != nil {
+			var commitError *service.CommitError
+			if strict || !errors.As(err, &commitError) || errors.Is(err, service.ErrRateLimited) || ctx.Err() != nil {
+				// stopping the iteration cancels the store's outstanding work
+				if timeout, _ := c.Config.GetTimeout(); timeout > 0 && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					return nil, fmt.Errorf("timeout of %s exceeded: %w", timeout, err)
+				}
+				return nil, err
 			}
-		case <-doneChan:
-			return c.writeChangelog(all, writer)
+			log.WithFields(log.Fields{"error": err}).Warn("Unable to process commit, commit omitted.")
+			omitted++
+			continue
 		}
+		all = append(all, ci)
+	}
+
+	if omitted > 0 {
+		log.WithFields(log.Fields{"count": omitted, "from": from, "to": to}).Warn("Some commits were omitted from the changelog.")
 	}
+	return all, nil
 }
 
-func (c *Changelog) GetGitURLs() (*model.GitURLs, error) {
-	gh := "https://github.com"
-	if c.Enterprise != nil {
-		gh = strings.TrimSuffix(*c.Enterprise, "/api")
+// newStore selects the Store for the configured provider, authenticating API clients via TokenSource or environment variables
+func (c *Changelog) newStore(ctx context.Context) (service.Store, error) {
+	if c.Store != nil {
+		return c.Store.WithConfig(c.Config), nil
+	}
+
+	provider := c.Config.GetProvider()
+	if !c.Config.GetPreferLocal() {
+		switch provider {
+		case model.GitLab:
+			token, err := c.lookupToken("GITLAB_TOKEN")
+			if err != nil {
+				return nil, err
+			}
+			httpClient, err := c.newHTTPClient()
+			if err != nil {
+				return nil, err
+			}
+			target, err := service.NewGitLabService(c.Config.GetBaseURL(), token, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			return target.WithConfig(c.Config), nil
+		case model.Gitea:
+			if c.Config.GetBaseURL() == "" {
+				return nil, errors.New("the gitea provider requires 'enterprise' to define the instance's base url")
+			}
+			token, err := c.lookupToken("GITEA_TOKEN")
+			if err != nil {
+				return nil, err
+			}
+			httpClient, err := c.newHTTPClient()
+			if err != nil {
+				return nil, err
+			}
+			target, err := service.NewGiteaService(c.Config.GetBaseURL(), token, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			return target.WithConfig(c.Config), nil
+		case model.BitbucketServer:
+			if c.Config.GetBaseURL() == "" {
+				return nil, errors.New("the bitbucket-server provider requires 'enterprise' to define the instance's base url")
+			}
+			token, err := c.lookupToken("BITBUCKET_TOKEN")
+			if err != nil {
+				return nil, err
+			}
+			httpClient, err := c.newHTTPClient()
+			if err != nil {
+				return nil, err
+			}
+			target, err := service.NewBitbucketServerService(c.Config.GetBaseURL(), token, httpClient)
+			if err != nil {
+				return nil, err
+			}
+			return target.WithConfig(c.Config), nil
+		}
 	}
-	baseURL, err := url.Parse(gh)
+
+	var client *github.Client
+	if c.Config.GetOffline() || provider != model.GitHub {
+		log.Debug("Offline mode, commits will be resolved from the local repository only.")
+	} else {
+		ts := c.TokenSource
+		if token, found := os.LookupEnv("GITHUB_TOKEN"); ts == nil && found {
+			ts = oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+		}
+		switch {
+		case ts != nil:
+			cl, e := c.newClient(ctx, ts)
+			if e != nil {
+				return nil, e
+			}
+			client = cl
+		case c.Config.GetPreferLocal():
+			log.Info("Environment variable GITHUB_TOKEN not found, pull request details will not be queried.")
+		default:
+			return nil, fmt.Errorf("%w: environment variable GITHUB_TOKEN not found", ErrMissingToken)
+		}
+	}
+
+	if c.Config.GetPreferLocal() {
+		return service.NewLocalGitService().WithClient(client).WithConfig(c.Config), nil
+	}
+	return service.NewGitHubService().WithClient(client).WithConfig(c.Config), nil
+}
+
+// defaultBranch determines the repository's default branch, falling back to HEAD when the store can't determine it
+func (c *Changelog) defaultBranch(ctx context.Context, target service.Store) (string, error) {
+	source, ok := target.(service.BranchSource)
+	if !ok {
+		log.Debug("Store doesn't report a default branch, changelog ends at HEAD.")
+		return "HEAD", nil
+	}
+
+	branch, err := source.DefaultBranch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("unable to determine the default branch: %w", err)
+	}
+
+	log.WithFields(log.Fields{"to": branch}).Info("Detected default branch.")
+	return branch, nil
+}
+
+// previousRelease detects the release tag preceding To, falling back to the commit before To when the store can't list
+// tags or no release precedes it
+func (c *Changelog) previousRelease(ctx context.Context, target service.Store) (string, error) {
+	previousCommit := c.To + "~1"
+	source, ok := target.(service.TagSource)
+	if !ok {
+		log.Debug("Store doesn't list tags, changelog begins from the previous commit.")
+		return previousCommit, nil
+	}
+
+	tag, err := service.PreviousRelease(ctx, source, c.Config, c.To)
+	if err != nil {
+		return "", err
+	}
+	if tag == "" {
+		log.WithFields(log.Fields{"to": c.To}).Info("No release tag precedes 'to', changelog begins from the previous commit.")
+		return previousCommit, nil
+	}
+
+	log.WithFields(log.Fields{"from": tag, "to": c.To}).Info("Detected previous release.")
+	return tag, nil
+}
+
+// nextVersion names the release of items, applying the most significant bump they warrant to the version of from when
+// it's a release tag, otherwise to the latest release tag preceding to (or 0.0.0 when there's none). The name keeps the
+// tag's prefix and "v" (added when there's no release, unless the prefix ends with it). It's empty when there are no items.
+// Failing to find the latest release isn't fatal to the changelog, so versions begin at 0.0.0 with a warning.
+func (c *Changelog) nextVersion(ctx context.Context, target service.Store, from string, to string, items []model.ChangeItem) string {
+	var bump model.Bump
+	for _, item := range items {
+		bump = max(bump, c.bumpOf(item))
+	}
+	if bump == 0 {
+		return ""
+	}
+
+	base := c.releaseVersion(from)
+	if source, ok := target.(service.TagSource); ok && base == nil {
+		latest, err := service.PreviousRelease(ctx, source, c.Config, to)
+		if err != nil {
+			log.WithFields(log.Fields{"to": to, "error": err}).Warn("Unable to find the latest release, next version begins from 0.0.0.")
+		}
+		from, base = latest, c.releaseVersion(latest)
+	}
+
+	prefix := c.Config.GetTagPrefix()
+	v := "v"
+	if base == nil {
+		base = &model.Version{}
+		if strings.HasSuffix(prefix, "v") {
+			v = ""
+		}
+	} else if !strings.HasPrefix(strings.TrimPrefix(from, prefix), "v") {
+		v = ""
+	}
+
+	next := prefix + v + base.Bump(bump).String()
+	log.WithFields(log.Fields{"from": from, "bump": bump.String(), "next": next}).Debug("Determined next version.")
+	return next
+}
+
+// releaseVersion parses the version of the release tag named by revision, which is nil when the config doesn't consider
+// revision a release tag (e.g. a branch, or a prerelease when skipping prereleases)
+func (c *Changelog) releaseVersion(revision string) *model.Version {
+	releases, err := service.ReleaseTags([]service.Tag{{Name: revision}}, c.Config)
+	if err != nil || len(releases) == 0 {
+		return nil
+	}
+	return releases[0].Version
+}
+
+// bumpOf classifies the version bump warranted by item. Breaking changes are major, otherwise the bump is mapped from
+// the item's group by config, otherwise derived from its conventional commit type, defaulting to patch. Items collapsed
+// from a pull request warrant at least the bump of each of their commits.
+func (c *Changelog) bumpOf(item model.ChangeItem) model.Bump {
+	message := ""
+	if item.CommitMessageRaw != nil {
+		message = *item.CommitMessageRaw
+	}
+
+	bump, conventional := model.ConventionalBump(message)
+	if bump != model.Major {
+		if mapped, ok := c.Config.Bumps[item.Group()]; ok {
+			bump = mapped
+		} else if !conventional {
+			bump = model.Patch
+		}
+	}
+	for _, commit := range item.Commits() {
+		bump = max(bump, c.bumpOf(commit))
+	}
+	return bump
+}
+
+// newHTTPClient creates a client for REST API stores which honors rate limits and retries transient failures,
+// caching responses on disk when enabled
+func (c *Changelog) newHTTPClient() (*http.Client, error) {
+	client, err := c.baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+	client.Transport = c.retryTransport(client.Transport)
+	return client, nil
+}
+
+// baseHTTPClient copies HTTPClient (or creates a default client), wrapping its transport with an on-disk cache of API
+// responses when caching is enabled
+func (c *Changelog) baseHTTPClient() (*http.Client, error) {
+	client := &http.Client{}
+	if c.HTTPClient != nil {
+		copied := *c.HTTPClient
+		client = &copied
+	}
+	if !c.Config.GetCache() {
+		return client, nil
+	}
+
+	dir, err := c.Config.GetCacheDir()
 	if err != nil {
 		return nil, err
 	}
-	create := func(op string) string {
-		// Create a copy to avoid mutating the shared baseURL
-		u := *baseURL
-		end := fmt.Sprintf("%s...%s%s", c.From, c.To, op)
-		u.Path = path.Join(u.Path, c.Owner, c.Repo, "compare", end)
-		return u.String()
-	}
-	return &model.GitURLs{
-		CompareURL: create(""),
-		DiffURL:    create(".diff"),
-		PatchURL:   create(".patch"),
-	}, nil
+	ttl, err := c.Config.GetCacheTTL()
+	if err != nil {
+		return nil, err
+	}
+
+	log.WithFields(log.Fields{"dir": dir, "ttl": ttl.String()}).Debug("Caching API responses.")
+	cache := service.NewCacheTransport(client.Transport, dir, ttl)
+	cache.Now = c.Now
+	client.Transport = cache
+	return client, nil
 }
 
-func wait(ch chan struct{}, wg *sync.WaitGroup) {
-	wg.Wait()
-	ch <- struct{}{}
+// retryTransport wraps base with retries of transient failures and waits for rate limits, bounding each attempt by the
+// configured request timeout
+func (c *Changelog) retryTransport(base http.RoundTripper) http.RoundTripper {
+	retry := service.NewRetryTransport(base)
+	retry.Now = c.Now
+	// prepare has already validated the request timeout
+	retry.Timeout, _ = c.Config.GetRequestTimeout()
+	return retry
 }
 
-func (c *Changelog) writeChangelog(all []model.ChangeItem, writer io.Writer) error {
+// lookupToken reads an optional API token from TokenSource, otherwise from the environment variable named by key
+func (c *Changelog) lookupToken(key string) (string, error) {
+	if c.TokenSource != nil {
+		token, err := c.TokenSource.Token()
+		if err != nil {
+			return "", err
+		}
+		return token.AccessToken, nil
+	}
+
+	token, found := os.LookupEnv(key)
+	if !found {
+		log.Infof("Environment variable %s not found, only public repositories are accessible.", key)
+	}
+	return token, nil
+}
+
+// newClient creates a GitHub API client authenticated by ts, targeting GitHub Enterprise when configured
+func (c *Changelog) newClient(ctx context.Context, ts oauth2.TokenSource) (*github.Client, error) {
+	base, err := c.baseHTTPClient()
+	if err != nil {
+		return nil, err
+	}
+
+	// the cache sits beneath oauth2, so cached responses are keyed by the authorization header
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, base)
+	tc := oauth2.NewClient(ctx, ts)
+	tc.Transport = c.retryTransport(tc.Transport)
+
+	if c.Config.Enterprise != nil && *c.Config.Enterprise != "" {
+		return github.NewEnterpriseClient(*c.Config.Enterprise, *c.Config.Enterprise, tc)
+	}
+
+	return github.NewClient(tc), nil
+}
+
+// GetGitURLs creates the compare, diff and patch locations for the range of this changelog
+func (c *Changelog) GetGitURLs() (*model.GitURLs, error) {
+	urls, err := c.Config.URLBuilder()
+	if err != nil {
+		return nil, err
+	}
+	return urls.GitURLs(c.From, c.To), nil
+}
+
+// templateData collapses (when resolving pull requests), sorts and groups items, binding them to the range of this changelog
+func (c *Changelog) templateData(all []model.ChangeItem) *model.TemplateData {
 	var compareURL = ""
 	var diffURL = ""
 	var patchURL = ""
 
+	// a range starting at the repository root has nothing to compare against
 	u, err := c.GetGitURLs()
 	if err != nil {
 		log.Warn("Unable to determine urls for compare, diff, and patch.")
-	} else {
+	} else if c.From != "" {
 		compareURL = u.CompareURL
 		diffURL = u.DiffURL
 		patchURL = u.PatchURL
 	}
 
-	switch *c.Config.SortDirection {
-	case model.Ascending:
-		sort.Sort(CommitAscendingSorter(all))
-	case model.Descending:
-		sort.Sort(CommitDescendingSorter(all))
+	if c.Config.GetResolveType() == model.PullRequests {
+		all = c.collapsePullRequests(all)
+	}
+
+	// stable sorting keeps commits sharing a timestamp in the order the store produced them
+	if c.Config.SortDirection != nil {
+		switch *c.Config.SortDirection {
+		case model.Ascending:
+			sort.Stable(CommitAscendingSorter(all))
+		case model.Descending:
+			sort.Stable(CommitDescendingSorter(all))
+		}
 	}
 
 	grouped := make(map[string][]model.ChangeItem)
@@ -215,7 +707,7 @@ func (c *Changelog) writeChangelog(all []model.ChangeItem, writer io.Writer) err
 		}
 	}
 
-	d := &model.TemplateData{
+	return &model.TemplateData{
 		PreviousVersion: c.From,
 		Version:         c.To,
 		Items:           all,
@@ -224,24 +716,159 @@ func (c *Changelog) writeChangelog(all []model.ChangeItem, writer io.Writer) err
 		PatchURL:        patchURL,
 		Grouped:         templateGroups,
 	}
+}
 
-	var tpl = defaultTemplate
+// Template returns the contents of the configured template, otherwise DefaultTemplate
+func (c *Changelog) Template() string {
 	if c.Config.Template != nil {
-		b, templateErr := os.ReadFile(*c.Config.Template)
-		if templateErr != nil {
+		b, err := os.ReadFile(*c.Config.Template)
+		if err != nil {
 			log.Warn("Unable to load template. Using default.")
 		} else {
 			log.Debug("Using custom template.")
-			tpl = string(b)
+			return string(b)
 		}
 	}
+	return DefaultTemplate
+}
 
+// HistoryTemplate returns the contents of the configured history template, otherwise DefaultHistoryTemplate
+func (c *Changelog) HistoryTemplate() string {
+	if c.Config.HistoryTemplate != nil {
+		b, err := os.ReadFile(*c.Config.HistoryTemplate)
+		if err != nil {
+			log.Warn("Unable to load history template. Using default.")
+		} else {
+			log.Debug("Using custom history template.")
+			return string(b)
+		}
+	}
+	return DefaultHistoryTemplate
+}
+
+// RenderHistory executes the text/template tpl with history, writing to the supplied writer. The "Release" template,
+// available to tpl for rendering each release, is defined by releaseTpl (e.g. DefaultTemplate).
+func RenderHistory(writer io.Writer, history *model.History, tpl string, releaseTpl string) error {
+	tmpl, err := template.New("Release").Parse(releaseTpl)
+	if err != nil {
+		return err
+	}
+	tmpl, err = tmpl.New("history").Parse(tpl)
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(writer, history)
+}
+
+// Render executes the text/template tpl with data, writing to the supplied writer
+func Render(writer io.Writer, data *model.TemplateData, tpl string) error {
 	tmpl, err := template.New("changelog").Parse(tpl)
 	if err != nil {
 		return err
 	}
 
-	return tmpl.Execute(writer, d)
+	return tmpl.Execute(writer, data)
+}
+
+// collapsePullRequests merges the commits of each pull request into a single ChangeItem describing the pull request.
+// The commits remain available to templates via Commits; commits which weren't introduced by a pull request are unchanged.
+func (c *Changelog) collapsePullRequests(all []model.ChangeItem) []model.ChangeItem {
+	pulls := make(map[string][]model.ChangeItem)
+	order := make([]string, 0)
+	collapsed := make([]model.ChangeItem, 0, len(all))
+	for _, item := range all {
+		key := ""
+		switch {
+		case !item.IsPull():
+		case item.PullNumber() > 0:
+			key = strconv.Itoa(item.PullNumber())
+		default:
+			key = item.PullURL()
+		}
+
+		if key == "" {
+			collapsed = append(collapsed, item)
+			continue
+		}
+		if _, ok := pulls[key]; !ok {
+			order = append(order, key)
+		}
+		pulls[key] = append(pulls[key], item)
+	}
+
+	// links to merge commits are omitted when the URLs config is invalid
+	urls, _ := c.Config.URLBuilder()
+	for _, key := range order {
+		collapsed = append(collapsed, c.summarizePullRequest(pulls[key], urls))
+	}
+
+	log.WithFields(log.Fields{
+		"commits": len(all),
+		"pulls":   len(order),
+		"items":   len(collapsed),
+	}).Debug("collapsed commits by pull request")
+
+	return collapsed
+}
+
+// summarizePullRequest creates a ChangeItem for the pull request which introduced commits, falling back to
+// the attributes of its latest commit when the store was unable to provide pull request details
+func (c *Changelog) summarizePullRequest(commits []model.ChangeItem, urls *model.URLBuilder) model.ChangeItem {
+	sort.Stable(CommitAscendingSorter(commits))
+	latest := commits[len(commits)-1]
+	details := latest
+	for _, commit := range commits {
+		if commit.PullTitleRaw != nil {
+			details = commit
+			break
+		}
+	}
+
+	item := model.ChangeItem{
+		AuthorRaw:        details.AuthorRaw,
+		AuthorURLRaw:     details.AuthorURLRaw,
+		CommitMessageRaw: details.CommitMessageRaw,
+		DateRaw:          latest.DateRaw,
+		IsPullRaw:        details.IsPullRaw,
+		PullURLRaw:       details.PullURLRaw,
+		PullSourceRaw:    details.PullSourceRaw,
+		CommitHashRaw:    latest.CommitHashRaw,
+		CommitURLRaw:     latest.CommitURLRaw,
+		PullNumberRaw:    details.PullNumberRaw,
+		PullTitleRaw:     details.PullTitleRaw,
+		PullAuthorRaw:    details.PullAuthorRaw,
+		PullAuthorURLRaw: details.PullAuthorURLRaw,
+		MergedAtRaw:      details.MergedAtRaw,
+		MergeCommitRaw:   details.MergeCommitRaw,
+		LabelsRaw:        details.LabelsRaw,
+		CommitsRaw:       commits,
+	}
+
+	if details.PullAuthor() != "" {
+		item.AuthorRaw = details.PullAuthorRaw
+		item.AuthorURLRaw = details.PullAuthorURLRaw
+	}
+	if details.PullTitle() != "" {
+		item.CommitMessageRaw = details.PullTitleRaw
+		item.GroupRaw = c.Config.FindGroup(details.PullTitle())
+	}
+	for i := 0; item.GroupRaw == nil && i < len(commits); i++ {
+		item.GroupRaw = commits[i].GroupRaw
+	}
+	if details.MergedAtRaw != nil {
+		item.DateRaw = details.MergedAtRaw
+	}
+	if mergeCommit := details.MergeCommit(); mergeCommit != "" {
+		item.CommitHashRaw = details.MergeCommitRaw
+		item.CommitURLRaw = nil
+		if urls != nil {
+			commitURL := urls.CommitURL(mergeCommit)
+			item.CommitURLRaw = &commitURL
+		}
+	}
+
+	return item
 }
 
 type CommitDescendingSorter []model.ChangeItem
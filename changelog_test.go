@@ -16,14 +16,28 @@ package changelog
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-github/v29/github"
+	"golang.org/x/oauth2"
 
 	"github.com/jimschubert/changelog/model"
+	"github.com/jimschubert/changelog/service"
 )
 
 func commits(items ...model.ChangeItem) *[]model.ChangeItem {
@@ -181,7 +195,7 @@ func TestCommitAscendingSorter(t *testing.T) {
 // 	}
 // }
 
-func TestChangelog_writeChangelog(t *testing.T) {
+func TestChangelog_Render(t *testing.T) {
 	p := func(s string) *string { return &s }
 	gp := func(arr []model.Grouping) []model.Grouping { return arr }
 	fromTimestamp := func(ts int64) *time.Time {
@@ -362,14 +376,852 @@ func TestChangelog_writeChangelog(t *testing.T) {
 				To:     tt.fields.To,
 			}
 			writer := &bytes.Buffer{}
-			err := c.writeChangelog(tt.args.all, writer)
+			err := Render(writer, c.templateData(tt.args.all), c.Template())
 			if (err != nil) != tt.wantErr {
-				t.Errorf("writeChangelog() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("Render() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if gotWriter := writer.String(); gotWriter != tt.wantWriter {
-				t.Errorf("writeChangelog() gotWriter = '''%v''', want '''%v'''", gotWriter, tt.wantWriter)
+				t.Errorf("Render() gotWriter = '''%v''', want '''%v'''", gotWriter, tt.wantWriter)
 			}
 		})
 	}
 }
+
+func TestChangelog_GetGitURLs(t *testing.T) {
+	p := func(s string) *string { return &s }
+	tests := []struct {
+		name   string
+		config *model.Config
+		want   model.GitURLs
+	}{
+		{"github",
+			&model.Config{Owner: "jimschubert", Repo: "changelog"},
+			model.GitURLs{
+				CompareURL: "https://github.com/jimschubert/changelog/compare/v0.1...v0.2",
+				DiffURL:    "https://github.com/jimschubert/changelog/compare/v0.1...v0.2.diff",
+				PatchURL:   "https://github.com/jimschubert/changelog/compare/v0.1...v0.2.patch",
+			},
+		},
+		{"github enterprise",
+			&model.Config{Owner: "jimschubert", Repo: "changelog", Enterprise: p("https://ghe.example.com/api")},
+			model.GitURLs{
+				CompareURL: "https://ghe.example.com/jimschubert/changelog/compare/v0.1...v0.2",
+				DiffURL:    "https://ghe.example.com/jimschubert/changelog/compare/v0.1...v0.2.diff",
+				PatchURL:   "https://ghe.example.com/jimschubert/changelog/compare/v0.1...v0.2.patch",
+			},
+		},
+		{"gitlab",
+			&model.Config{Owner: "group/sub", Repo: "project", Provider: model.GitLab.Ptr()},
+			model.GitURLs{
+				CompareURL: "https://gitlab.com/group/sub/project/-/compare/v0.1...v0.2",
+				DiffURL:    "https://gitlab.com/group/sub/project/-/compare/v0.1...v0.2.diff",
+				PatchURL:   "https://gitlab.com/group/sub/project/-/compare/v0.1...v0.2.patch",
+			},
+		},
+		{"self-managed gitlab",
+			&model.Config{Owner: "group", Repo: "project", Provider: model.GitLab.Ptr(), Enterprise: p("https://gitlab.example.com/api/v4")},
+			model.GitURLs{
+				CompareURL: "https://gitlab.example.com/group/project/-/compare/v0.1...v0.2",
+				DiffURL:    "https://gitlab.example.com/group/project/-/compare/v0.1...v0.2.diff",
+				PatchURL:   "https://gitlab.example.com/group/project/-/compare/v0.1...v0.2.patch",
+			},
+		},
+		{"gitea",
+			&model.Config{Owner: "owner", Repo: "project", Provider: model.Gitea.Ptr(), Enterprise: p("https://codeberg.org/")},
+			model.GitURLs{
+				CompareURL: "https://codeberg.org/owner/project/compare/v0.1...v0.2",
+				DiffURL:    "https://codeberg.org/owner/project/compare/v0.1...v0.2.diff",
+				PatchURL:   "https://codeberg.org/owner/project/compare/v0.1...v0.2.patch",
+			},
+		},
+		{"bitbucket server",
+			&model.Config{Owner: "PROJ", Repo: "project", Provider: model.BitbucketServer.Ptr(), Enterprise: p("https://bitbucket.example.com/rest/api/1.0")},
+			model.GitURLs{
+				CompareURL: "https://bitbucket.example.com/projects/PROJ/repos/project/compare/commits?sourceBranch=v0.2&targetBranch=v0.1",
+				DiffURL:    "https://bitbucket.example.com/projects/PROJ/repos/project/compare/diff?sourceBranch=v0.2&targetBranch=v0.1",
+				PatchURL:   "https://bitbucket.example.com/rest/api/latest/projects/PROJ/repos/project/patch?since=v0.1&until=v0.2",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Changelog{Config: tt.config, From: "v0.1", To: "v0.2"}
+			got, err := c.GetGitURLs()
+			if err != nil {
+				t.Fatalf("GetGitURLs() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("GetGitURLs() = %v, want %v", *got, tt.want)
+			}
+		})
+	}
+}
+
+// newBitbucketServer is a stand-in for the Bitbucket Server REST API serving a feature commit and an excluded commit
+func newBitbucketServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/project/commits", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected Authorization header %q", r.Header.Get("Authorization"))
+		}
+		_, _ = w.Write([]byte(`{"isLastPage": true, "values": [
+			{"id": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "message": "feat: add gadgets", "author": {"name": "jim"},
+			 "committerTimestamp": 1767434400000, "parents": [{"id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}]},
+			{"id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "message": "wip: widgets", "author": {"name": "jim"},
+			 "committerTimestamp": 1767348000000, "parents": [{"id": "0000000000000000000000000000000000000000"}]}
+		]}`))
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/project/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"isLastPage": true, "values": [{"id": 7, "title": "Add gadgets", "state": "MERGED", "author": {"user": {"name": "octocat"}}}]}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestChangelog_Generate_bitbucketServer(t *testing.T) {
+	server := newBitbucketServer(t)
+	t.Setenv("BITBUCKET_TOKEN", "secret")
+
+	sort := model.Ascending
+	c := &Changelog{
+		Config: &model.Config{
+			Owner:         "PROJ",
+			Repo:          "project",
+			Provider:      model.BitbucketServer.Ptr(),
+			Enterprise:    &server.URL,
+			SortDirection: &sort,
+			Groupings:     []model.Grouping{{Name: "Features", Patterns: []string{"^feat:"}}},
+			Exclude:       []string{"^wip:"},
+		},
+		From: "v1.0.0",
+		To:   "v1.1.0",
+	}
+
+	writer := &bytes.Buffer{}
+	if err := c.Generate(writer); err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	want := fmt.Sprintf(`## v1.1.0
+
+### Features
+
+* [bbbbbbbbbb](%[1]s/projects/PROJ/repos/project/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb) feat: add gadgets ([contributed](%[1]s/projects/PROJ/repos/project/pull-requests/7) by octocat)
+
+<em>For more details, see <a href="%[1]s/projects/PROJ/repos/project/compare/commits?sourceBranch=v1.1.0&targetBranch=v1.0.0">v1.0.0..v1.1.0</a></em>
+`, server.URL)
+	if got := writer.String(); got != want {
+		t.Errorf("Generate() = '''%v''', want '''%v'''", got, want)
+	}
+}
+
+func TestChangelog_Collect(t *testing.T) {
+	server := newBitbucketServer(t)
+	t.Setenv("BITBUCKET_TOKEN", "secret")
+
+	c := &Changelog{
+		Config: &model.Config{
+			Owner:      "PROJ",
+			Repo:       "project",
+			Provider:   model.BitbucketServer.Ptr(),
+			Enterprise: &server.URL,
+			Groupings:  []model.Grouping{{Name: "Features", Patterns: []string{"^feat:"}}},
+			Exclude:    []string{"^wip:"},
+		},
+		From: "v1.0.0",
+		To:   "v1.1.0",
+	}
+
+	data, err := c.Collect(t.Context())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if data.Version != "v1.1.0" || data.PreviousVersion != "v1.0.0" {
+		t.Errorf("Collect() versions = %v..%v, want v1.0.0..v1.1.0", data.PreviousVersion, data.Version)
+	}
+	if len(data.Items) != 1 || data.Items[0].Title() != "feat: add gadgets" {
+		t.Errorf("Collect() items = %v, want only the feature", data.Items)
+	}
+	if len(data.Grouped) != 1 || data.Grouped[0].Name != "Features" {
+		t.Errorf("Collect() groups = %v, want Features", data.Grouped)
+	}
+
+	// one collection renders to several formats
+	for tpl, want := range map[string]string{
+		"{{range .Items}}* {{.Title}}\n{{end}}":              "* feat: add gadgets\n",
+		"{{range .Grouped}}{{.Name}}: {{len .Items}}{{end}}": "Features: 1",
+	} {
+		writer := &bytes.Buffer{}
+		if err := Render(writer, data, tpl); err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if got := writer.String(); got != want {
+			t.Errorf("Render() = %q, want %q", got, want)
+		}
+	}
+}
+
+// roundTripFunc is an http.RoundTripper standing in for a proxy
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// staticStore is a Store yielding fixed items
+type staticStore struct {
+	config *model.Config
+	items  []model.ChangeItem
+}
+
+func (s *staticStore) WithClient(client *github.Client) service.Store {
+	return s
+}
+
+func (s *staticStore) WithConfig(config *model.Config) service.Store {
+	s.config = config
+	return s
+}
+
+func (s *staticStore) GetContextual() *service.Contextual {
+	return nil
+}
+
+func (s *staticStore) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	return func(yield func(model.ChangeItem, error) bool) {
+		for _, item := range s.items {
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// releaseStore is a Store with release tags, yielding a single item titled by each queried range
+type releaseStore struct {
+	tags []service.Tag
+}
+
+func (s *releaseStore) WithClient(client *github.Client) service.Store {
+	return s
+}
+
+func (s *releaseStore) WithConfig(config *model.Config) service.Store {
+	return s
+}
+
+func (s *releaseStore) GetContextual() *service.Contextual {
+	return nil
+}
+
+func (s *releaseStore) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	return func(yield func(model.ChangeItem, error) bool) {
+		title := from + ".." + to
+		hash := "0123456789abcdef"
+		yield(model.ChangeItem{CommitMessageRaw: &title, CommitHashRaw: &hash}, nil)
+	}
+}
+
+func (s *releaseStore) Tags(ctx context.Context) ([]service.Tag, error) {
+	return s.tags, nil
+}
+
+func (s *releaseStore) Precedes(ctx context.Context, revision string, to string) (bool, error) {
+	return true, nil
+}
+
+func (s *releaseStore) DefaultBranch(ctx context.Context) (string, error) {
+	return "main", nil
+}
+
+func TestChangelog_CollectHistory(t *testing.T) {
+	store := &releaseStore{tags: []service.Tag{{Name: "v1.10.0"}, {Name: "v1.2.0"}, {Name: "nightly"}, {Name: "v2.0.0"}, {Name: "v1.0.0"}}}
+	tests := []struct {
+		name       string
+		unreleased bool
+		want       []string
+	}{
+		{"releases between consecutive tags", false, []string{"v1.10.0..v2.0.0", "v1.2.0..v1.10.0", "v1.0.0..v1.2.0", "..v1.0.0"}},
+		{"unreleased changes", true, []string{"v2.0.0..Unreleased", "v1.10.0..v2.0.0", "v1.2.0..v1.10.0", "v1.0.0..v1.2.0", "..v1.0.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Changelog{Config: &model.Config{Owner: "o", Repo: "r", Unreleased: &tt.unreleased}, Store: store}
+
+			history, err := c.CollectHistory(t.Context())
+			if err != nil {
+				t.Fatalf("CollectHistory() error = %v", err)
+			}
+			got := make([]string, 0, len(history.Releases))
+			for _, release := range history.Releases {
+				got = append(got, release.PreviousVersion+".."+release.Version)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CollectHistory() releases = %v, want %v", got, tt.want)
+			}
+			if tt.unreleased && history.Releases[0].Items[0].Title() != "v2.0.0..main" {
+				t.Errorf("CollectHistory() unreleased items = %v, want changes up to the default branch", history.Releases[0].Items)
+			}
+			if tt.unreleased && history.Releases[0].NextVersion != "v2.0.1" {
+				t.Errorf("CollectHistory() unreleased NextVersion = %q, want v2.0.1", history.Releases[0].NextVersion)
+			}
+			// the first release spans from the repository root, so there's nothing to compare it against
+			if first := history.Releases[len(history.Releases)-1]; first.CompareURL != "" || first.PreviousRef != nil {
+				t.Errorf("CollectHistory() first release compare = %q, previous ref = %v, want neither", first.CompareURL, first.PreviousRef)
+			}
+
+			writer := bytes.NewBufferString("")
+			if err := RenderHistory(writer, history, c.HistoryTemplate(), c.Template()); err != nil {
+				t.Fatalf("RenderHistory() error = %v", err)
+			}
+			if got := strings.Count(writer.String(), "\n## "); got != len(tt.want) || !strings.HasPrefix(writer.String(), "# Changelog\n\n## ") {
+				t.Errorf("RenderHistory() rendered %d releases, want %d:\n%s", got, len(tt.want), writer.String())
+			}
+			if got := strings.Count(writer.String(), "For more details"); got != len(tt.want)-1 {
+				t.Errorf("RenderHistory() linked %d comparisons, want %d:\n%s", got, len(tt.want)-1, writer.String())
+			}
+		})
+	}
+}
+
+func TestChangelog_Collect_store(t *testing.T) {
+	title := "Add widgets"
+	store := &staticStore{items: []model.ChangeItem{{CommitMessageRaw: &title}}}
+	config := &model.Config{Owner: "o", Repo: "r"}
+	c := &Changelog{Config: config, From: "v1", To: "v2", Store: store}
+
+	data, err := c.Collect(t.Context())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if store.config != config {
+		t.Errorf("Collect() didn't apply the config to the injected store")
+	}
+	if len(data.Items) != 1 || data.Items[0].Title() != title {
+		t.Errorf("Collect() items = %v, want the injected store's items", data.Items)
+	}
+
+	// a store which can't report its default branch or tags ends at HEAD, beginning from the commit before it
+	c = &Changelog{Config: config, Store: store}
+	data, err = c.Collect(t.Context())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if data.Version != "HEAD" || data.PreviousVersion != "HEAD~1" {
+		t.Errorf("Collect() range = %s..%s, want HEAD~1..HEAD", data.PreviousVersion, data.Version)
+	}
+}
+
+// taggedStore is a staticStore with release tags, each preceding any revision
+type taggedStore struct {
+	staticStore
+	tags []service.Tag
+}
+
+func (s *taggedStore) WithConfig(config *model.Config) service.Store {
+	s.config = config
+	return s
+}
+
+func (s *taggedStore) Tags(ctx context.Context) ([]service.Tag, error) {
+	return s.tags, nil
+}
+
+func (s *taggedStore) Precedes(ctx context.Context, revision string, to string) (bool, error) {
+	return true, nil
+}
+
+func TestChangelog_NextVersion(t *testing.T) {
+	change := func(message string, group string) model.ChangeItem {
+		item := model.ChangeItem{CommitMessageRaw: &message}
+		if group != "" {
+			item.GroupRaw = &group
+		}
+		return item
+	}
+	pull := change("Add widgets", "")
+	pull.CommitsRaw = []model.ChangeItem{change("fix: typo", ""), change("feat!: remove gadgets", "")}
+
+	tests := []struct {
+		name   string
+		from   string
+		prefix string
+		bumps  map[string]model.Bump
+		tags   []service.Tag
+		items  []model.ChangeItem
+		want   string
+	}{
+		{"no changes", "v1.2.3", "", nil, nil, nil, ""},
+		{"fix", "v1.2.3", "", nil, nil, []model.ChangeItem{change("fix: typo", "")}, "v1.2.4"},
+		{"feature", "v1.2.3", "", nil, nil, []model.ChangeItem{change("feat(cli): add widgets", "")}, "v1.3.0"},
+		{"breaking change", "v1.2.3", "", nil, nil, []model.ChangeItem{change("fix!: reject gadgets", "")}, "v2.0.0"},
+		{"most significant change", "v1.2.3", "", nil, nil, []model.ChangeItem{change("fix: typo", ""), change("feat: add widgets", ""), change("docs: widgets", "")}, "v1.3.0"},
+		{"unconventional change", "v1.2.3", "", nil, nil, []model.ChangeItem{change("Add widgets", "")}, "v1.2.4"},
+		{"group mapping", "v1.2.3", "", map[string]model.Bump{"Features": model.Minor}, nil, []model.ChangeItem{change("Add widgets", "Features")}, "v1.3.0"},
+		{"group mapping overrides type", "v1.2.3", "", map[string]model.Bump{"Fixes": model.Patch}, nil, []model.ChangeItem{change("feat: add widgets", "Fixes")}, "v1.2.4"},
+		{"breaking change overrides group mapping", "v1.2.3", "", map[string]model.Bump{"Docs": model.Patch}, nil, []model.ChangeItem{change("docs!: remove gadgets", "Docs")}, "v2.0.0"},
+		{"commits of a pull request", "v1.2.3", "", nil, nil, []model.ChangeItem{pull}, "v2.0.0"},
+		{"prerelease", "v2.0.0-rc.1", "", nil, nil, []model.ChangeItem{change("fix: typo", "")}, "v2.0.0"},
+		{"version without v", "1.2.3", "", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "1.3.0"},
+		{"tag prefix", "api/v1.2.3", "api/", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "api/v1.3.0"},
+		{"no release", "HEAD~1", "", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "v0.1.0"},
+		{"no release with tag prefix", "HEAD~1", "api/", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "api/v0.1.0"},
+		{"no release with v prefix", "HEAD~1", "v", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "v0.1.0"},
+		{"no release with prefix ending in v", "HEAD~1", "api/v", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "api/v0.1.0"},
+		{"v prefix", "v1.2.3", "v", nil, nil, []model.ChangeItem{change("feat: add widgets", "")}, "v1.3.0"},
+		{"latest release preceding to", "abc1234", "", nil, []service.Tag{{Name: "v1.0.0"}, {Name: "v1.1.0"}}, []model.ChangeItem{change("feat: add widgets", "")}, "v1.2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefix := tt.prefix
+			config := &model.Config{Owner: "o", Repo: "r", TagPrefix: &prefix, Bumps: tt.bumps}
+			store := &taggedStore{staticStore: staticStore{items: tt.items}, tags: tt.tags}
+			c := &Changelog{Config: config, From: tt.from, To: "main", Store: store}
+
+			got, err := c.NextVersion(t.Context())
+			if err != nil {
+				t.Fatalf("NextVersion() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NextVersion() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelog_Collect_nextVersion(t *testing.T) {
+	message := "feat: add widgets"
+	referencing := filepath.Join(t.TempDir(), "next.tmpl")
+	if err := os.WriteFile(referencing, []byte("## {{.NextVersion}}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		template *string
+		want     string
+	}{
+		{"default template", nil, ""},
+		{"template referencing NextVersion", &referencing, "v1.2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &model.Config{Owner: "o", Repo: "r", Template: tt.template}
+			store := &taggedStore{staticStore: staticStore{items: []model.ChangeItem{{CommitMessageRaw: &message}}}, tags: []service.Tag{{Name: "v1.1.0"}}}
+			c := &Changelog{Config: config, From: "abc1234", To: "main", Store: store}
+
+			data, err := c.Collect(t.Context())
+			if err != nil {
+				t.Fatalf("Collect() error = %v", err)
+			}
+			if data.NextVersion != tt.want {
+				t.Errorf("Collect() NextVersion = %q, want %q", data.NextVersion, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangelog_Collect_localStore(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := &object.Signature{Name: "Jim Schubert", Email: "jim@example.com", When: time.Unix(1767348000, 0)}
+	for _, message := range []string{"Initial commit", "Add widgets"} {
+		if _, err := worktree.Commit(message, &git.CommitOptions{Author: signature, AllowEmptyCommits: true}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the store is injected without a client, as when pull requests aren't resolved
+	offline := true
+	c := &Changelog{
+		Config: &model.Config{Owner: "o", Repo: "r", Path: &dir, Offline: &offline},
+		From:   "HEAD~1",
+		To:     "HEAD",
+		Store:  service.NewLocalGitService(),
+	}
+	data, err := c.Collect(t.Context())
+	if err != nil {
+		t.Fatalf("Collect() error = %v", err)
+	}
+	if len(data.Items) != 1 || data.Items[0].Title() != "Add widgets" {
+		t.Errorf("Collect() items = %v, want the local commit", data.Items)
+	}
+}
+
+func TestChangelog_Collect_injected(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/o/r/compare/v1...v2", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if got := r.Header.Get("Authorization"); got != "Bearer injected" {
+			t.Errorf("Authorization = %q, want the injected token", got)
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`{"total_commits": 1, "commits": [{"sha": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			"commit": {"message": "Add widgets", "author": {"date": "2026-01-02T10:00:00Z"}}, "author": {"login": "octocat"}}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "from-environment")
+
+	proxied := 0
+	now := time.Date(2026, time.January, 2, 10, 0, 0, 0, time.UTC)
+	cache := true
+	cacheDir := t.TempDir()
+	c := &Changelog{
+		Config: &model.Config{Owner: "o", Repo: "r", Enterprise: &server.URL, Cache: &cache, CacheDir: &cacheDir},
+		From:   "v1",
+		To:     "v2",
+		HTTPClient: &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			// refs are also described, but only the cacheable comparison is counted
+			if strings.Contains(req.URL.Path, "/compare/") {
+				proxied++
+			}
+			return http.DefaultTransport.RoundTrip(req)
+		})},
+		TokenSource: oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "injected"}),
+		Now:         func() time.Time { return now },
+	}
+
+	// the second collection is served by the cache, until the injected clock passes its ttl
+	for i, want := range []int{1, 1, 2} {
+		if i == 2 {
+			now = now.Add(2 * time.Hour)
+		}
+		data, err := c.Collect(t.Context())
+		if err != nil {
+			t.Fatalf("Collect() error = %v", err)
+		}
+		if len(data.Items) != 1 || data.Items[0].Author() != "octocat" {
+			t.Errorf("Collect() items = %v, want the compared commit", data.Items)
+		}
+		if requests != want || proxied != want {
+			t.Errorf("collection %d made %d requests (%d proxied), want %d", i+1, requests, proxied, want)
+		}
+	}
+}
+
+func TestChangelog_Collect_errors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+	}))
+	defer server.Close()
+
+	local := true
+	tests := []struct {
+		name    string
+		config  *model.Config
+		token   bool
+		wantErr error
+	}{
+		{"github requires a token", &model.Config{Owner: "o", Repo: "r"}, false, ErrMissingToken},
+		{"unknown github refs", &model.Config{Owner: "o", Repo: "r", Enterprise: &server.URL}, true, ErrRefNotFound},
+		{"unknown local refs", &model.Config{Owner: "o", Repo: "r", PreferLocal: &local}, false, ErrRefNotFound},
+		{"invalid exclude pattern", &model.Config{Owner: "o", Repo: "r", PreferLocal: &local, Exclude: []string{"wip("}}, false, ErrInvalidPattern},
+		{"invalid grouping pattern", &model.Config{Owner: "o", Repo: "r", PreferLocal: &local, Groupings: []model.Grouping{{Name: "Fixes", Patterns: []string{"[fix"}}}}, false, ErrInvalidPattern},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GITHUB_TOKEN", "")
+			if !tt.token {
+				_ = os.Unsetenv("GITHUB_TOKEN")
+			}
+			c := &Changelog{Config: tt.config, From: "v0.0.0-missing", To: "v0.0.1-missing"}
+
+			_, err := c.Collect(t.Context())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Collect() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestChangelog_Collect_rateLimited(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/repos/o/r/compare/v1...v2", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"total_commits": 1, "commits": [{"sha": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa",
+			"commit": {"message": "Add widgets", "author": {"date": "2026-01-02T10:00:00Z"}}, "author": {"login": "octocat"}}]}`))
+	})
+	mux.HandleFunc("/api/v3/repos/o/r/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/pulls", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10))
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "API rate limit exceeded"}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	// even when lenient, a rate limit fails the run rather than omitting every remaining commit
+	c := &Changelog{
+		Config: &model.Config{Owner: "o", Repo: "r", Enterprise: &server.URL, ResolveType: model.PullRequests.Ptr()},
+		From:   "v1",
+		To:     "v2",
+	}
+	data, err := c.Collect(t.Context())
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Collect() = %v, %v, want %v", data, err, ErrRateLimited)
+	}
+}
+
+func TestChangelog_Collect_timeouts(t *testing.T) {
+	// the compare API never responds, so only a deadline ends collection
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+	t.Setenv("GITHUB_TOKEN", "token")
+
+	p := func(s string) *string {
+		return &s
+	}
+	tests := []struct {
+		name           string
+		requestTimeout *string
+		timeout        *string
+		cancelParent   bool
+		wantErr        error
+	}{
+		{"request timeout", p("50ms"), nil, false, context.DeadlineExceeded},
+		{"run timeout", p("1m"), p("50ms"), false, context.DeadlineExceeded},
+		{"cancelled parent", p("1m"), nil, true, context.Canceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Changelog{
+				Config: &model.Config{Owner: "o", Repo: "r", Enterprise: &server.URL, RequestTimeout: tt.requestTimeout, Timeout: tt.timeout},
+				From:   "v1",
+				To:     "v2",
+			}
+			ctx, cancel := context.WithCancel(t.Context())
+			defer cancel()
+			if tt.cancelParent {
+				time.AfterFunc(50*time.Millisecond, cancel)
+			}
+
+			start := time.Now()
+			_, err := c.Collect(ctx)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Collect() error = %v, want %v", err, tt.wantErr)
+			}
+			if elapsed := time.Since(start); elapsed > 10*time.Second {
+				t.Errorf("Collect() took %v, want it to end at the deadline", elapsed)
+			}
+		})
+	}
+}
+
+func TestChangelog_Generate_commitErrors(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/project/commits", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"isLastPage": true, "values": [
+			{"id": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "message": "Add gadgets", "author": {"name": "jim"},
+			 "committerTimestamp": 1767434400000, "parents": [{"id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}]},
+			{"id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "message": "Add widgets", "author": {"name": "jim"},
+			 "committerTimestamp": 1767348000000, "parents": [{"id": "0000000000000000000000000000000000000000"}]}
+		]}`))
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/project/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"isLastPage": true, "values": []}`))
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/PROJ/repos/project/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/pull-requests", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"errors": [{"message": "bad request"}]}`, http.StatusBadRequest)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	t.Setenv("BITBUCKET_TOKEN", "")
+
+	tests := []struct {
+		name    string
+		strict  bool
+		wantErr bool
+	}{
+		{"lenient runs omit the commit", false, false},
+		{"strict runs fail", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sort := model.Ascending
+			c := &Changelog{
+				Config: &model.Config{
+					Owner:         "PROJ",
+					Repo:          "project",
+					Provider:      model.BitbucketServer.Ptr(),
+					Enterprise:    &server.URL,
+					SortDirection: &sort,
+					Strict:        &tt.strict,
+				},
+				From: "v1.0.0",
+				To:   "v1.1.0",
+			}
+
+			writer := &bytes.Buffer{}
+			err := c.Generate(writer)
+			if tt.wantErr {
+				var commitError *service.CommitError
+				if !errors.As(err, &commitError) || commitError.SHA != "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb" {
+					t.Errorf("Generate() error = %v, want a CommitError for bbbbbbbbbb", err)
+				}
+				if writer.Len() > 0 {
+					t.Errorf("Generate() wrote %q despite failing", writer.String())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+			if got := writer.String(); !strings.Contains(got, "Add widgets") || strings.Contains(got, "Add gadgets") {
+				t.Errorf("Generate() = %q, want only the commit whose pull request lookup succeeded", got)
+			}
+		})
+	}
+}
+
+func TestChangelog_collapsePullRequests(t *testing.T) {
+	p := func(s string) *string { return &s }
+	n := func(i int) *int { return &i }
+	at := func(day int) *time.Time {
+		d := time.Date(2026, time.January, day, 10, 0, 0, 0, time.UTC)
+		return &d
+	}
+	isPull := true
+	config := &model.Config{
+		Owner:       "o",
+		Repo:        "r",
+		ResolveType: model.PullRequests.Ptr(),
+		Groupings:   []model.Grouping{{Name: "Features", Patterns: []string{"(?i)^add\\b"}}},
+	}
+
+	pullCommit := func(sha string, day int, message string) model.ChangeItem {
+		return model.ChangeItem{
+			AuthorRaw:        p("committer"),
+			CommitMessageRaw: p(message),
+			DateRaw:          at(day),
+			CommitHashRaw:    p(sha),
+			CommitURLRaw:     p("https://github.com/o/r/commit/" + sha),
+			IsPullRaw:        &isPull,
+			PullURLRaw:       p("https://github.com/o/r/pull/7"),
+			PullSourceRaw:    model.PullFromAssociation.Ptr(),
+			PullNumberRaw:    n(7),
+			PullTitleRaw:     p("Add gadgets"),
+			PullAuthorRaw:    p("octocat"),
+			PullAuthorURLRaw: p("https://github.com/octocat"),
+			MergedAtRaw:      at(5),
+			MergeCommitRaw:   p("mmmmmmmmmm"),
+			LabelsRaw:        []string{"enhancement"},
+		}
+	}
+	direct := model.ChangeItem{CommitMessageRaw: p("Direct commit"), DateRaw: at(3), CommitHashRaw: p("dddddddddd")}
+	titleOnly := model.ChangeItem{
+		CommitMessageRaw: p("Fix widgets (#9)"),
+		DateRaw:          at(4),
+		CommitHashRaw:    p("eeeeeeeeee"),
+		CommitURLRaw:     p("https://github.com/o/r/commit/eeeeeeeeee"),
+		IsPullRaw:        &isPull,
+		PullURLRaw:       p("https://github.com/o/r/pull/9"),
+		PullNumberRaw:    n(9),
+	}
+
+	c := &Changelog{Config: config, From: "v1", To: "v2"}
+	got := c.collapsePullRequests([]model.ChangeItem{
+		pullCommit("bbbbbbbbbb", 2, "Implement gadget storage"),
+		direct,
+		pullCommit("aaaaaaaaaa", 1, "Scaffold gadgets"),
+		titleOnly,
+	})
+
+	if len(got) != 3 {
+		t.Fatalf("collapsePullRequests() = %d items, want 3", len(got))
+	}
+	if got[0].CommitHash() != "dddddddddd" || got[0].IsPull() {
+		t.Errorf("collapsePullRequests() direct commit = %#v, want unchanged", &got[0])
+	}
+
+	pull := got[1]
+	for name, pair := range map[string][2]any{
+		"Title":       {pull.Title(), "Add gadgets"},
+		"Author":      {pull.Author(), "octocat"},
+		"AuthorURL":   {pull.AuthorURL(), "https://github.com/octocat"},
+		"PullNumber":  {pull.PullNumber(), 7},
+		"Date":        {pull.Date(), *at(5)},
+		"CommitHash":  {pull.CommitHash(), "mmmmmmmmmm"},
+		"CommitURL":   {pull.CommitURL(), "https://github.com/o/r/commit/mmmmmmmmmm"},
+		"Group":       {pull.Group(), "Features"},
+		"Labels":      {fmt.Sprint(pull.Labels()), "[enhancement]"},
+		"Commits":     {len(pull.Commits()), 2},
+		"FirstCommit": {pull.Commits()[0].CommitHash(), "aaaaaaaaaa"},
+	} {
+		if pair[0] != pair[1] {
+			t.Errorf("collapsePullRequests() %s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+
+	fallback := got[2]
+	if fallback.Title() != "Fix widgets (#9)" || fallback.CommitHash() != "eeeeeeeeee" || len(fallback.Commits()) != 1 {
+		t.Errorf("collapsePullRequests() without pull request details = %#v, want the commit's attributes", &fallback)
+	}
+}
+
+func TestChangelog_Render_pullRequests(t *testing.T) {
+	p := func(s string) *string { return &s }
+	n := func(i int) *int { return &i }
+	isPull := true
+	date := time.Date(2026, time.January, 2, 10, 0, 0, 0, time.UTC)
+
+	tpl := filepath.Join(t.TempDir(), "changelog.tmpl")
+	err := os.WriteFile(tpl, []byte(`{{range .Items}}* #{{.PullNumber}} {{.Title}} {{.Labels}}
+{{range .Commits}}  - {{.CommitHashShort}} {{.Title}}
+{{end}}{{end}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commit := func(sha string, message string) model.ChangeItem {
+		return model.ChangeItem{
+			CommitMessageRaw: p(message),
+			DateRaw:          &date,
+			CommitHashRaw:    p(sha),
+			IsPullRaw:        &isPull,
+			PullNumberRaw:    n(7),
+			PullTitleRaw:     p("Add gadgets"),
+			LabelsRaw:        []string{"enhancement", "gadgets"},
+		}
+	}
+
+	c := &Changelog{
+		Config: &model.Config{
+			Owner:         "o",
+			Repo:          "r",
+			ResolveType:   model.PullRequests.Ptr(),
+			SortDirection: model.Ascending.Ptr(),
+			Template:      &tpl,
+		},
+		From: "v1",
+		To:   "v2",
+	}
+	writer := &bytes.Buffer{}
+	data := c.templateData([]model.ChangeItem{commit("aaaaaaaaaa", "Scaffold gadgets"), commit("bbbbbbbbbb", "Implement gadget storage")})
+	if err := Render(writer, data, c.Template()); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want := "* #7 Add gadgets [enhancement gadgets]\n  - aaaaaaaaaa Scaffold gadgets\n  - bbbbbbbbbb Implement gadget storage\n"
+	if got := writer.String(); got != want {
+		t.Errorf("Render() = '''%v''', want '''%v'''", got, want)
+	}
+}
@@ -15,14 +15,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"time"
 
 	"github.com/alecthomas/kong"
 	log "github.com/sirupsen/logrus"
 
 	"github.com/jimschubert/changelog"
 	"github.com/jimschubert/changelog/model"
+	"github.com/jimschubert/changelog/service"
 )
 
 //nolint:unused
@@ -38,23 +43,64 @@ type Options struct {
 
 	Repo string `short:"r" help:"GitHub Repo name" env:"GITHUB_REPO" default:""`
 
-	From string `short:"f" help:"Begin changelog from this commit or tag"`
+	From string `short:"f" help:"Begin changelog from this commit or tag (defaults to the previous release tag)"`
 
-	To string `short:"t" help:"End changelog at this commit or tag" default:"master"`
+	To string `short:"t" help:"End changelog at this commit or tag (defaults to the repository's default branch)"`
 
 	Config *string `short:"c" help:"Config file location for more advanced options beyond defaults"`
 
 	Local *bool `short:"l" help:"Prefer local commits when gathering commit logs (as opposed to querying via API)"`
 
+	Path *string `short:"p" help:"Path to the local git repository, or any directory within it (defaults to the current directory)" type:"path"`
+
+	Offline *bool `help:"Resolve commits from the local repository only, without querying the API (implies --local)"`
+
 	MaxCommits *int `name:"max" help:"The maximum number of commits to include"`
 
+	Strict *bool `help:"Fail when any commit can't be processed, rather than omitting it with a warning"`
+
+	Concurrency *int `help:"The maximum number of commits processed (and API requests made) at once (default: 8)"`
+
+	Cache *bool `help:"Cache API responses on disk, revalidating via ETag once expired (--no-cache bypasses a cache enabled by config)" negatable:""`
+
+	ClearCache bool `name:"clear-cache" help:"Remove cached API responses before generating the changelog"`
+
+	RequestTimeout *time.Duration `name:"request-timeout" help:"The maximum duration of an individual API request, e.g. 30s (default: 10s)"`
+
+	Timeout *time.Duration `help:"The maximum duration of the run, after which outstanding requests are cancelled and the run fails, e.g. 5m"`
+
+	TagPrefix *string `name:"tag-prefix" help:"Prefix of release tags, removed before parsing their semantic version, e.g. api/"`
+
+	TagPattern *string `name:"tag-pattern" help:"Regular expression which release tags must match"`
+
+	SkipPrerelease *bool `name:"skip-prerelease" help:"Ignore prerelease tags (e.g. v1.0.0-rc.1) when detecting the previous release" negatable:""`
+
+	History bool `help:"Generate a changelog of every release, from each release tag to the next and the oldest from the repository root (--from is ignored)"`
+
+	Unreleased *bool `help:"Include changes following the newest release tag as an Unreleased section of --history" negatable:""`
+
 	Version kong.VersionFlag `short:"v" help:"Display version information"`
+
+	Generate struct{} `cmd:"" default:"withargs" help:"Generate a changelog (the default command)"`
+
+	NextVersion struct{} `cmd:"" name:"next-version" help:"Print the version of the next release, bumping the previous release tag by the changes since"`
 }
 
+// exit codes distinguish failures which callers (e.g. CI scripts) may handle differently
+const (
+	exitFailure        = 1
+	exitUsage          = 2
+	exitMissingToken   = 3
+	exitRefNotFound    = 4
+	exitRateLimited    = 5
+	exitTimeout        = 6
+	exitInvalidPattern = 7
+)
+
 var opts Options
 
 func main() {
-	kong.Parse(&opts,
+	cli := kong.Parse(&opts,
 		kong.Name(projectName),
 		kong.Description("Generate a changelog from GitHub commits"),
 		kong.Vars{"version": fmt.Sprintf("%s %s (%s)", projectName, version, commit)},
@@ -66,13 +112,56 @@ func main() {
 	err := validateConfig(config)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+		os.Exit(exitUsage)
 	}
 
 	config.MaxCommits = opts.MaxCommits
 	if opts.Local != nil {
 		config.PreferLocal = opts.Local
 	}
+	if opts.Path != nil {
+		config.Path = opts.Path
+	}
+	if opts.Offline != nil {
+		config.Offline = opts.Offline
+	}
+	if opts.Strict != nil {
+		config.Strict = opts.Strict
+	}
+	if opts.Concurrency != nil {
+		config.Concurrency = opts.Concurrency
+	}
+	if opts.Cache != nil {
+		config.Cache = opts.Cache
+	}
+	if opts.RequestTimeout != nil {
+		requestTimeout := opts.RequestTimeout.String()
+		config.RequestTimeout = &requestTimeout
+	}
+	if opts.Timeout != nil {
+		timeout := opts.Timeout.String()
+		config.Timeout = &timeout
+	}
+	if opts.TagPrefix != nil {
+		config.TagPrefix = opts.TagPrefix
+	}
+	if opts.TagPattern != nil {
+		config.TagPattern = opts.TagPattern
+	}
+	if opts.SkipPrerelease != nil {
+		config.SkipPrerelease = opts.SkipPrerelease
+	}
+	if opts.Unreleased != nil {
+		config.Unreleased = opts.Unreleased
+	}
+
+	if opts.ClearCache {
+		err = clearCache(config)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", err)
+			os.Exit(exitFailure)
+		}
+	}
 
 	log.WithFields(log.Fields{"config": config}).Debug("Loaded config.")
 
@@ -82,10 +171,53 @@ func main() {
 		To:     opts.To,
 	}
 
-	err = changes.Generate(os.Stdout)
+	// an interrupt cancels outstanding requests rather than abandoning them
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	switch {
+	case cli.Command() == "next-version":
+		err = printNextVersion(ctx, &changes)
+	case opts.History:
+		err = changes.GenerateHistory(ctx, os.Stdout)
+	default:
+		err = changes.GenerateContext(ctx, os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: generation failed: %s\n", err)
+		stop()
+		os.Exit(exitCode(err))
+	}
+}
+
+// printNextVersion writes the version of the release made by the changes between from and to
+func printNextVersion(ctx context.Context, changes *changelog.Changelog) error {
+	next, err := changes.NextVersion(ctx)
 	if err != nil {
-		fmt.Fprintf(os.Stdout, "generation failed: %s", err)
-		os.Exit(1)
+		return err
+	}
+	if next == "" {
+		return fmt.Errorf("no changes between %s and %s to release", changes.From, changes.To)
+	}
+	_, err = fmt.Fprintln(os.Stdout, next)
+	return err
+}
+
+// exitCode maps known failures to their exit code
+func exitCode(err error) int {
+	switch {
+	case errors.Is(err, changelog.ErrMissingToken):
+		return exitMissingToken
+	case errors.Is(err, changelog.ErrRefNotFound):
+		return exitRefNotFound
+	case errors.Is(err, changelog.ErrRateLimited):
+		return exitRateLimited
+	case errors.Is(err, context.DeadlineExceeded):
+		return exitTimeout
+	case errors.Is(err, changelog.ErrInvalidPattern):
+		return exitInvalidPattern
+	default:
+		return exitFailure
 	}
 }
 
@@ -112,6 +244,15 @@ func validateConfig(opts *model.Config) error {
 	return nil
 }
 
+func clearCache(config *model.Config) error {
+	dir, err := config.GetCacheDir()
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{"dir": dir}).Debug("Clearing cached API responses.")
+	return service.ClearCache(dir)
+}
+
 func initLogging() {
 	logLevel, ok := os.LookupEnv("LOG_LEVEL")
 	if !ok {
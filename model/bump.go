@@ -0,0 +1,109 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Bump is the component of a semantic version which a change increments. Values are ordered by significance,
+// so the bump warranted by several changes is the max of theirs.
+type Bump uint8
+
+const (
+	// Patch is a backward compatible fix
+	Patch Bump = 1 << iota
+	// Minor is backward compatible functionality
+	Minor Bump = 1 << iota
+	// Major is a breaking change
+	Major Bump = 1 << iota
+)
+
+// conventionalPattern matches the header of a conventional commit, e.g. feat(parser)!: drop support for tabs
+var conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\([^)]*\))?(!)?:\s`)
+
+// breakingPattern matches the footer of a conventional commit which describes a breaking change
+var breakingPattern = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+
+// ConventionalBump classifies a commit message following https://www.conventionalcommits.org: breaking changes
+// (marked by "!" or a BREAKING CHANGE footer) are Major, "feat" is Minor and "fix" or "perf" is Patch.
+// ok is false when the message doesn't determine a bump, e.g. "docs: fix typo" or a message which isn't conventional.
+func ConventionalBump(message string) (bump Bump, ok bool) {
+	if breakingPattern.MatchString(message) {
+		return Major, true
+	}
+
+	match := conventionalPattern.FindStringSubmatch(message)
+	if match == nil {
+		return 0, false
+	}
+	if match[2] == "!" {
+		return Major, true
+	}
+	switch strings.ToLower(match[1]) {
+	case "feat":
+		return Minor, true
+	case "fix", "perf":
+		return Patch, true
+	default:
+		return 0, false
+	}
+}
+
+// MarshalJSON converts Bump into a string representation sufficient for JSON. Unlike other enumerations, the receiver
+// isn't a pointer, as the values of Config.Bumps aren't addressable.
+func (b Bump) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + b.String() + `"`), nil
+}
+
+// UnmarshalJSON converts a JSON formatted character array into Bump
+func (b *Bump) UnmarshalJSON(data []byte) error {
+	s := strings.TrimSpace(string(data))
+	switch strings.ToLower(strings.Trim(s, `"`)) {
+	case "major":
+		*b = Major
+	case "minor":
+		*b = Minor
+	case "patch":
+		*b = Patch
+	default:
+		return fmt.Errorf("unknown bump %q", s)
+	}
+	return nil
+}
+
+func (b *Bump) UnmarshalYAML(data []byte) error {
+	return b.UnmarshalJSON(data)
+}
+
+func (b Bump) MarshalYAML() ([]byte, error) {
+	return b.MarshalJSON()
+}
+
+// String displays a human readable representation of the Bump values
+func (b Bump) String() string {
+	switch b {
+	case Major:
+		return "major"
+	case Minor:
+		return "minor"
+	case Patch:
+		return "patch"
+	default:
+		return "none"
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConventionalBump(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Bump
+		wantOk  bool
+	}{
+		{"feature", "feat: add next-version", Minor, true},
+		{"scoped feature", "feat(cli): add next-version", Minor, true},
+		{"fix", "fix: handle empty ranges", Patch, true},
+		{"performance", "perf(local): reuse the repository", Patch, true},
+		{"breaking marker", "refactor!: rename options", Major, true},
+		{"scoped breaking marker", "feat(api)!: remove Generate", Major, true},
+		{"breaking footer", "feat: rename options\n\nBREAKING CHANGE: --max is now --max-commits", Major, true},
+		{"hyphenated breaking footer", "fix: reject tabs\n\nBREAKING-CHANGE: tabs are invalid", Major, true},
+		{"other type", "docs: fix typo", 0, false},
+		{"not conventional", "Add next-version", 0, false},
+		{"breaking change in title isn't a footer", "Document BREAKING CHANGE: footers", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := ConventionalBump(tt.message)
+			if got != tt.want || ok != tt.wantOk {
+				t.Errorf("ConventionalBump() = %v, %v, want %v, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestBump_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    Bump
+		wantErr bool
+	}{
+		{"unmarshal major", []byte(`"major"`), Major, false},
+		{"unmarshal Minor", []byte(`"Minor"`), Minor, false},
+		{"unmarshal patch", []byte(`"patch"`), Patch, false},
+		{"unmarshal unknown", []byte(`"breaking"`), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Bump
+			if err := got.UnmarshalJSON(tt.b); (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Bumps(t *testing.T) {
+	var c Config
+	if err := json.Unmarshal([]byte(`{"bumps": {"Features": "minor", "Breaking": "major"}}`), &c); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if c.Bumps["Features"] != Minor || c.Bumps["Breaking"] != Major {
+		t.Errorf("Bumps = %v, want map[Breaking:major Features:minor]", c.Bumps)
+	}
+
+	b, err := json.Marshal(&c)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	var roundTrip Config
+	if err := json.Unmarshal(b, &roundTrip); err != nil || roundTrip.Bumps["Features"] != Minor {
+		t.Errorf("Bumps round trip = %v (%v), want Features mapped to minor", roundTrip.Bumps, err)
+	}
+}
@@ -41,6 +41,9 @@ type ChangeItem struct {
 	// When IsPullRaw=true, this will point to the source of the pull request
 	PullURLRaw *string `json:"pull_url"`
 
+	// When IsPullRaw=true, this records how the pull request was identified
+	PullSourceRaw *PullSource `json:"pull_source,omitempty"`
+
 	// The commit's full SHA1 hash
 	CommitHashRaw *string `json:"commit"`
 
@@ -49,6 +52,30 @@ type ChangeItem struct {
 
 	// An optional group identifier
 	GroupRaw *string `json:"group"`
+
+	// When IsPullRaw=true, the number of the pull request
+	PullNumberRaw *int `json:"pull_number,omitempty"`
+
+	// When IsPullRaw=true, the title of the pull request
+	PullTitleRaw *string `json:"pull_title,omitempty"`
+
+	// When IsPullRaw=true, the author of the pull request
+	PullAuthorRaw *string `json:"pull_author,omitempty"`
+
+	// When IsPullRaw=true, the URL to the pull request author's profile
+	PullAuthorURLRaw *string `json:"pull_author_url,omitempty"`
+
+	// When IsPullRaw=true, the date on which the pull request was merged
+	MergedAtRaw *time.Time `json:"merged_at,omitempty"`
+
+	// When IsPullRaw=true, the full SHA1 hash of the commit which merged the pull request
+	MergeCommitRaw *string `json:"merge_commit,omitempty"`
+
+	// When IsPullRaw=true, the labels applied to the pull request
+	LabelsRaw []string `json:"labels,omitempty"`
+
+	// When resolving pull requests, the commits which make up the pull request
+	CommitsRaw []ChangeItem `json:"commits,omitempty"`
 }
 
 // Author or empty string
@@ -108,6 +135,14 @@ func (ci *ChangeItem) PullURL() string {
 	return ""
 }
 
+// PullSource is the method which identified the pull request, or zero when the commit isn't a pull request
+func (ci *ChangeItem) PullSource() PullSource {
+	if ci.PullSourceRaw != nil {
+		return *ci.PullSourceRaw
+	}
+	return 0
+}
+
 // CommitHash or empty string
 func (ci *ChangeItem) CommitHash() string {
 	if ci.CommitHashRaw != nil {
@@ -153,6 +188,70 @@ func (ci *ChangeItem) Group() string {
 	return ""
 }
 
+// PullNumber is the number of the pull request, or 0
+func (ci *ChangeItem) PullNumber() int {
+	if ci.PullNumberRaw != nil {
+		return *ci.PullNumberRaw
+	}
+	return 0
+}
+
+// PullTitle is the title of the pull request, or empty string
+func (ci *ChangeItem) PullTitle() string {
+	if ci.PullTitleRaw != nil {
+		return *ci.PullTitleRaw
+	}
+	return ""
+}
+
+// PullAuthor is the author of the pull request, or empty string
+func (ci *ChangeItem) PullAuthor() string {
+	if ci.PullAuthorRaw != nil {
+		return *ci.PullAuthorRaw
+	}
+	return ""
+}
+
+// PullAuthorURL is the URL to the pull request author's profile, or empty string
+func (ci *ChangeItem) PullAuthorURL() string {
+	if ci.PullAuthorURLRaw != nil {
+		return *ci.PullAuthorURLRaw
+	}
+	return ""
+}
+
+// MergedAt is the date on which the pull request was merged, or the zero time
+func (ci *ChangeItem) MergedAt() time.Time {
+	if ci.MergedAtRaw != nil {
+		return *ci.MergedAtRaw
+	}
+	return time.Time{}
+}
+
+// MergeCommit is the full SHA1 hash of the commit which merged the pull request, or empty string
+func (ci *ChangeItem) MergeCommit() string {
+	if ci.MergeCommitRaw != nil {
+		return *ci.MergeCommitRaw
+	}
+	return ""
+}
+
+// Labels applied to the pull request, or an empty slice
+func (ci *ChangeItem) Labels() []string {
+	if ci.LabelsRaw != nil {
+		return ci.LabelsRaw
+	}
+	return []string{}
+}
+
+// Commits which make up the pull request when resolving pull requests, or an empty slice
+func (ci *ChangeItem) Commits() []ChangeItem {
+	if ci.CommitsRaw != nil {
+		return ci.CommitsRaw
+	}
+	return []ChangeItem{}
+}
+
 // GoString displays debuggable format of ChangeItem
 func (ci *ChangeItem) GoString() string {
 	var builder strings.Builder
@@ -19,13 +19,18 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 	log "github.com/sirupsen/logrus"
 )
 
+// ErrInvalidPattern is returned by ValidatePatterns when an exclude or grouping pattern isn't a valid regular expression
+var ErrInvalidPattern = errors.New("invalid pattern")
+
 // Grouping allows assigning a grouping name with a set of regex patterns or texts.
 // These patterns are evaluated against commit titles and, if resolving pull requests, labels.
 type Grouping struct {
@@ -61,9 +66,15 @@ type Config struct {
 	// will be ignored.
 	Exclude []string `json:"exclude,omitempty"`
 
-	// Optional base url when targeting GitHub Enterprise
+	// Provider is the git hosting service queried for commits, one of "github" (default), "gitlab", "gitea" (including Forgejo), or "bitbucket-server"
+	Provider *Provider `json:"provider,omitempty"`
+
+	// Optional base url when targeting GitHub Enterprise or a self-managed instance of another provider
 	Enterprise *string `json:"enterprise,omitempty"`
 
+	// URLs define how links to commits, pull requests and comparisons are created; defaults to the layout of Provider
+	URLs *URLConfig `json:"urls,omitempty"`
+
 	// Custom template following Go text/template syntax
 	// For more details, see https://golang.org/pkg/text/template/
 	Template *string `json:"template,omitempty"`
@@ -74,8 +85,67 @@ type Config struct {
 	// PreferLocal defines whether commits may be queried locally. Requires executing from within a Git repository.
 	PreferLocal *bool `json:"local,omitempty"`
 
+	// Path to the local repository, or any directory within it, used when PreferLocal. Defaults to the current working directory.
+	Path *string `json:"path,omitempty"`
+
+	// Offline restricts processing to the local repository, never querying the API for supplemental data. Implies PreferLocal.
+	Offline *bool `json:"offline,omitempty"`
+
 	// MaxCommits defines the maximum number of commits to be processed.
 	MaxCommits *int `json:"max_commits,omitempty"`
+
+	// Strict fails the run when any commit can't be processed (e.g. a pull request lookup fails), rather than omitting the commit with a warning.
+	Strict *bool `json:"strict,omitempty"`
+
+	// Concurrency defines the maximum number of commits processed (and API requests made) at once. Defaults to 8.
+	Concurrency *int `json:"concurrency,omitempty"`
+
+	// Cache enables an on-disk cache of API responses, which are revalidated via ETag once older than CacheTTL
+	Cache *bool `json:"cache,omitempty"`
+
+	// CacheDir is the location of cached API responses. Defaults to "changelog" within the user's cache directory.
+	CacheDir *string `json:"cache_dir,omitempty"`
+
+	// CacheTTL is the duration (e.g. "30m") for which cached API responses are used without revalidation. Defaults to 1h.
+	CacheTTL *string `json:"cache_ttl,omitempty"`
+
+	// RequestTimeout is the duration (e.g. "30s") after which an individual API request is abandoned. Defaults to 10s.
+	RequestTimeout *string `json:"request_timeout,omitempty"`
+
+	// Timeout is the duration (e.g. "5m") after which the run fails, cancelling any outstanding requests. Defaults to no deadline.
+	Timeout *string `json:"timeout,omitempty"`
+
+	// TagPrefix is removed from tag names before parsing them as semantic versions (e.g. "api/" for tags such as "api/v1.2.3").
+	// Tags without the prefix aren't considered releases. A "v" following the prefix is optional.
+	TagPrefix *string `json:"tag_prefix,omitempty"`
+
+	// TagPattern is a regular expression which tag names must match to be considered releases
+	TagPattern *string `json:"tag_pattern,omitempty"`
+
+	// SkipPrerelease excludes tags with prerelease versions (e.g. v1.0.0-rc.1) from being considered releases
+	SkipPrerelease *bool `json:"skip_prerelease,omitempty"`
+
+	// HistoryTemplate is the path to a custom template for history, following Go text/template syntax. Each release may be
+	// rendered via the "Release" template, which is defined by Template (or the default template).
+	HistoryTemplate *string `json:"history_template,omitempty"`
+
+	// Unreleased includes changes following the newest release tag as an "Unreleased" release of the history
+	Unreleased *bool `json:"unreleased,omitempty"`
+
+	// Bumps maps grouping names to the version bump ("major", "minor" or "patch") warranted by their changes, taking
+	// precedence over conventional commit types. Breaking changes are always "major".
+	Bumps map[string]Bump `json:"bumps,omitempty"`
+}
+
+// compiledPatterns holds the regular expressions of Exclude, and of each Grouping in order
+type compiledPatterns struct {
+	exclude   []*regexp.Regexp
+	groupings []compiledGrouping
+}
+
+type compiledGrouping struct {
+	name     string
+	patterns []*regexp.Regexp
 }
 
 // Load a Config from path
@@ -108,8 +178,71 @@ func (c *Config) Validate() error {
 	return nil
 }
 
-// GetPreferLocal returns the user-specified preference for local commit querying, otherwise the default of 'false'
+// GetResolveType returns the user-specified resolve type, otherwise the default of Commits
+func (c *Config) GetResolveType() ResolveType {
+	if c.ResolveType == nil {
+		return Commits
+	}
+
+	return *c.ResolveType
+}
+
+// GetProvider returns the user-specified git hosting service, otherwise the default of GitHub
+func (c *Config) GetProvider() Provider {
+	if c.Provider == nil {
+		return GitHub
+	}
+
+	return *c.Provider
+}
+
+// GetBaseURL returns the web location of the hosting service: Enterprise (without any API path), otherwise the
+// provider's public host. Gitea and Bitbucket Server have no public host, so an empty string is returned unless Enterprise is defined.
+func (c *Config) GetBaseURL() string {
+	if c.Enterprise != nil && *c.Enterprise != "" {
+		base := strings.TrimSuffix(*c.Enterprise, "/")
+		for _, suffix := range []string{"/rest/api/1.0", "/rest/api/latest", "/api/v1", "/api/v3", "/api/v4", "/api"} {
+			base = strings.TrimSuffix(base, suffix)
+		}
+		return base
+	}
+
+	switch c.GetProvider() {
+	case GitHub:
+		return "https://github.com"
+	case GitLab:
+		return "https://gitlab.com"
+	default:
+		return ""
+	}
+}
+
+// URLBuilder creates links for this repository using the URLs config, otherwise the layout of the configured Provider
+func (c *Config) URLBuilder() (*URLBuilder, error) {
+	scheme := c.GetProvider().String()
+	base := c.GetBaseURL()
+	var overrides URLTemplates
+	if c.URLs != nil {
+		if c.URLs.Scheme != "" && !strings.EqualFold(c.URLs.Scheme, scheme) {
+			// a different scheme describes another host (e.g. a mirror), so the provider's base url doesn't apply
+			scheme = c.URLs.Scheme
+			base = ""
+		}
+		if c.URLs.Base != "" {
+			base = c.URLs.Base
+		}
+		overrides = c.URLs.URLTemplates
+	}
+
+	return NewURLBuilder(scheme, base, c.Owner, c.Repo, overrides)
+}
+
+// GetPreferLocal returns the user-specified preference for local commit querying (always true when Offline), otherwise the default of 'false'
 func (c *Config) GetPreferLocal() bool {
+	if c.GetOffline() {
+		return true
+	}
+
 	if c.PreferLocal == nil {
 		return false
 	}
@@ -117,17 +250,197 @@ func (c *Config) GetPreferLocal() bool {
 	return *c.PreferLocal
 }
 
+// GetPath returns the user-specified local repository path, otherwise the current working directory
+func (c *Config) GetPath() (string, error) {
+	if c.Path == nil || *c.Path == "" {
+		return os.Getwd()
+	}
+
+	return *c.Path, nil
+}
+
+// GetOffline returns the user-specified preference for offline processing, otherwise the default of 'false'
+func (c *Config) GetOffline() bool {
+	if c.Offline == nil {
+		return false
+	}
+
+	return *c.Offline
+}
+
+// GetCache returns the user-specified preference for caching API responses, otherwise the default of 'false'
+func (c *Config) GetCache() bool {
+	if c.Cache == nil {
+		return false
+	}
+
+	return *c.Cache
+}
+
+// GetCacheDir returns the user-specified cache directory, otherwise "changelog" within the user's cache directory
+func (c *Config) GetCacheDir() (string, error) {
+	if c.CacheDir != nil && *c.CacheDir != "" {
+		return *c.CacheDir, nil
+	}
+
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "changelog"), nil
+}
+
+// GetCacheTTL returns the user-specified duration for which cached API responses are fresh, otherwise the default of 1 hour
+func (c *Config) GetCacheTTL() (time.Duration, error) {
+	if c.CacheTTL == nil || *c.CacheTTL == "" {
+		return time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(*c.CacheTTL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid cache_ttl %q: %w", *c.CacheTTL, err)
+	}
+	return ttl, nil
+}
+
+// GetRequestTimeout returns the user-specified duration for an individual API request, otherwise the default of 10 seconds
+func (c *Config) GetRequestTimeout() (time.Duration, error) {
+	if c.RequestTimeout == nil || *c.RequestTimeout == "" {
+		return 10 * time.Second, nil
+	}
+
+	timeout, err := time.ParseDuration(*c.RequestTimeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid request_timeout %q: %w", *c.RequestTimeout, err)
+	}
+	if timeout <= 0 {
+		return 0, fmt.Errorf("invalid request_timeout %q: must be positive", *c.RequestTimeout)
+	}
+	return timeout, nil
+}
+
+// GetTimeout returns the user-specified deadline for the whole run, otherwise 0 (no deadline)
+func (c *Config) GetTimeout() (time.Duration, error) {
+	if c.Timeout == nil || *c.Timeout == "" {
+		return 0, nil
+	}
+
+	timeout, err := time.ParseDuration(*c.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout %q: %w", *c.Timeout, err)
+	}
+	if timeout < 0 {
+		return 0, fmt.Errorf("invalid timeout %q: must not be negative", *c.Timeout)
+	}
+	return timeout, nil
+}
+
 // GetMaxCommits returns the user-specified preference for maximum commit count, otherwise the default of 500
 func (c *Config) GetMaxCommits() int {
 	if c.MaxCommits == nil {
-		// This default matches the maximum defined in GitHub's compare API.
-		// see https://developer.github.com/v3/repos/commits/#compare-two-commits
-		return 250
+		return 500
 	}
 
 	return *c.MaxCommits
 }
 
+// GetStrict returns the user-specified preference for failing on commits which can't be processed, otherwise the default of 'false'
+func (c *Config) GetStrict() bool {
+	if c.Strict == nil {
+		return false
+	}
+
+	return *c.Strict
+}
+
+// GetConcurrency returns the user-specified limit of commits processed at once, otherwise the default of 8
+func (c *Config) GetConcurrency() int {
+	if c.Concurrency == nil || *c.Concurrency < 1 {
+		return 8
+	}
+
+	return *c.Concurrency
+}
+
+// GetTagPrefix returns the user-specified prefix of release tags, otherwise an empty string
+func (c *Config) GetTagPrefix() string {
+	if c.TagPrefix == nil {
+		return ""
+	}
+
+	return *c.TagPrefix
+}
+
+// GetTagPattern returns the user-specified pattern which release tags must match, otherwise nil (all tags match)
+func (c *Config) GetTagPattern() (*regexp.Regexp, error) {
+	if c.TagPattern == nil || *c.TagPattern == "" {
+		return nil, nil
+	}
+
+	re, err := regexp.Compile(*c.TagPattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag_pattern %q: %w", *c.TagPattern, err)
+	}
+	return re, nil
+}
+
+// GetSkipPrerelease returns the user-specified preference for ignoring prerelease tags, otherwise the default of 'false'
+func (c *Config) GetSkipPrerelease() bool {
+	if c.SkipPrerelease == nil {
+		return false
+	}
+
+	return *c.SkipPrerelease
+}
+
+// GetUnreleased returns the user-specified preference for including unreleased changes in history, otherwise the default of 'false'
+func (c *Config) GetUnreleased() bool {
+	if c.Unreleased == nil {
+		return false
+	}
+
+	return *c.Unreleased
+}
+
+// ValidatePatterns reports Exclude and Groupings patterns which aren't valid regular expressions with ErrInvalidPattern
+func (c *Config) ValidatePatterns() error {
+	_, err := c.compilePatterns()
+	return err
+}
+
+// compiledPatterns compiles the current patterns, omitting (with a warning) those which are invalid
+func (c *Config) compiledPatterns() *compiledPatterns {
+	patterns, err := c.compilePatterns()
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("Invalid patterns are ignored.")
+	}
+	return patterns
+}
+
+// compilePatterns compiles every valid pattern, joining the errors of those which are invalid
+func (c *Config) compilePatterns() (*compiledPatterns, error) {
+	var errs []error
+	compile := func(kind string, patterns []string) []*regexp.Regexp {
+		compiled := make([]*regexp.Regexp, 0, len(patterns))
+		for _, pattern := range patterns {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%w in %s: %w", ErrInvalidPattern, kind, err))
+				continue
+			}
+			compiled = append(compiled, re)
+		}
+		return compiled
+	}
+
+	patterns := &compiledPatterns{exclude: compile("exclude", c.Exclude), groupings: make([]compiledGrouping, 0, len(c.Groupings))}
+	for _, grouping := range c.Groupings {
+		compiled := compile(fmt.Sprintf("grouping %q", grouping.Name), grouping.Patterns)
+		patterns.groupings = append(patterns.groupings, compiledGrouping{name: grouping.Name, patterns: compiled})
+	}
+	return patterns, errors.Join(errs...)
+}
+
 // ShouldExcludeByText checks if the given text matches any exclude pattern
 // Note: Patterns are compiled on each call rather than cached. While this has a minor performance
 // cost, it ensures correctness when Groupings or Exclude are modified after loading (since Config
@@ -136,10 +449,9 @@ func (c *Config) ShouldExcludeByText(text *string) bool {
 	if text == nil || len(c.Exclude) == 0 {
 		return false
 	}
-	for _, pattern := range c.Exclude {
-		re := regexp.MustCompile(pattern)
-		if re.Match([]byte(*text)) {
-			log.WithFields(log.Fields{"text": *text, "pattern": pattern}).Debug("exclude via pattern")
+	for _, re := range c.compiledPatterns().exclude {
+		if re.MatchString(*text) {
+			log.WithFields(log.Fields{"text": *text, "pattern": re.String()}).Debug("exclude via pattern")
 			return true
 		}
 	}
@@ -157,11 +469,10 @@ func (c *Config) FindGroup(commitMessage string) *string {
 
 	title := strings.Split(commitMessage, "\n")[0]
 
-	for i := range c.Groupings {
-		for _, pattern := range c.Groupings[i].Patterns {
-			re := regexp.MustCompile(pattern)
-			if re.Match([]byte(title)) {
-				grouping := c.Groupings[i].Name
+	for _, compiled := range c.compiledPatterns().groupings {
+		for _, re := range compiled.patterns {
+			if re.MatchString(title) {
+				grouping := compiled.name
 				log.WithFields(log.Fields{"grouping": grouping, "title": title}).Debug("found group name for commit")
 				return &grouping
 			}
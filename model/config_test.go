@@ -15,6 +15,7 @@
 package model
 
 import (
+	"errors"
 	"fmt"
 	"hash/fnv"
 	"math/rand"
@@ -22,6 +23,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 	"github.com/stretchr/testify/assert"
@@ -328,3 +330,132 @@ func TestConfig_FindGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestConfig_GetCacheTTL(t *testing.T) {
+	p := func(s string) *string {
+		return &s
+	}
+	tests := []struct {
+		name    string
+		ttl     *string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"defaults to an hour", nil, time.Hour, false},
+		{"empty defaults to an hour", p(""), time.Hour, false},
+		{"parses durations", p("15m"), 15 * time.Minute, false},
+		{"rejects invalid durations", p("daily"), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{CacheTTL: tt.ttl}
+			got, err := c.GetCacheTTL()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetCacheTTL() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("GetCacheTTL() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_timeouts(t *testing.T) {
+	p := func(s string) *string {
+		return &s
+	}
+	tests := []struct {
+		name               string
+		config             *Config
+		wantRequestTimeout time.Duration
+		wantTimeout        time.Duration
+		wantErr            bool
+	}{
+		{"defaults", &Config{}, 10 * time.Second, 0, false},
+		{"parses durations", &Config{RequestTimeout: p("45s"), Timeout: p("5m")}, 45 * time.Second, 5 * time.Minute, false},
+		{"rejects invalid request timeouts", &Config{RequestTimeout: p("0s")}, 0, 0, true},
+		{"rejects invalid timeouts", &Config{Timeout: p("soon")}, 10 * time.Second, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requestTimeout, requestErr := tt.config.GetRequestTimeout()
+			timeout, err := tt.config.GetTimeout()
+			if gotErr := requestErr != nil || err != nil; gotErr != tt.wantErr {
+				t.Errorf("GetRequestTimeout() error = %v, GetTimeout() error = %v, wantErr %v", requestErr, err, tt.wantErr)
+			}
+			if requestTimeout != tt.wantRequestTimeout || timeout != tt.wantTimeout {
+				t.Errorf("GetRequestTimeout() = %v, GetTimeout() = %v, want %v and %v", requestTimeout, timeout, tt.wantRequestTimeout, tt.wantTimeout)
+			}
+		})
+	}
+}
+
+func TestConfig_GetCacheDir(t *testing.T) {
+	dir := "/tmp/changelog-cache"
+	got, err := (&Config{CacheDir: &dir}).GetCacheDir()
+	if err != nil || got != dir {
+		t.Errorf("GetCacheDir() = %v, %v, want %v", got, err, dir)
+	}
+
+	t.Setenv("XDG_CACHE_HOME", "/tmp/xdg-cache")
+	t.Setenv("HOME", "/tmp/home")
+	got, err = (&Config{}).GetCacheDir()
+	if err != nil || filepath.Base(got) != "changelog" {
+		t.Errorf("GetCacheDir() = %v, %v, want a changelog directory within the user's cache directory", got, err)
+	}
+}
+
+func TestConfig_ValidatePatterns(t *testing.T) {
+	tests := []struct {
+		name      string
+		exclude   []string
+		groupings []Grouping
+		wantErr   bool
+	}{
+		{"valid patterns", []string{"^(?i)wip\\b"}, []Grouping{{Name: "Fixes", Patterns: []string{"\\bfix\\b"}}}, false},
+		{"invalid exclude pattern", []string{"wip("}, nil, true},
+		{"invalid grouping pattern", nil, []Grouping{{Name: "Fixes", Patterns: []string{"[fix"}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Config{Exclude: tt.exclude, Groupings: tt.groupings}
+			err := c.ValidatePatterns()
+			if (err != nil) != tt.wantErr || (tt.wantErr && !errors.Is(err, ErrInvalidPattern)) {
+				t.Errorf("ValidatePatterns() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfig_invalidPatterns(t *testing.T) {
+	// without ValidatePatterns, invalid patterns are ignored rather than panicking
+	c := &Config{
+		Exclude:   []string{"wip(", "^wip"},
+		Groupings: []Grouping{{Name: "Fixes", Patterns: []string{"[fix", "fix"}}},
+	}
+	title := "wip: fix widgets"
+	if !c.ShouldExcludeByText(&title) {
+		t.Errorf("ShouldExcludeByText() = false, want valid patterns matched")
+	}
+	if got := c.FindGroup(title); got == nil || *got != "Fixes" {
+		t.Errorf("FindGroup() = %v, want Fixes", got)
+	}
+}
+
+func TestConfig_modifiedPatterns(t *testing.T) {
+	c := &Config{Exclude: []string{"^wip"}, Groupings: []Grouping{{Name: "Fixes", Patterns: []string{"^fix"}}}}
+	if err := c.ValidatePatterns(); err != nil {
+		t.Fatalf("ValidatePatterns() error = %v", err)
+	}
+
+	c.Exclude = []string{"^draft"}
+	c.Groupings = []Grouping{{Name: "Features", Patterns: []string{"^feat"}}}
+	draft := "draft: widgets"
+	if !c.ShouldExcludeByText(&draft) {
+		t.Errorf("ShouldExcludeByText() = false, want modified Exclude evaluated")
+	}
+	if got := c.FindGroup("feat: widgets"); got == nil || *got != "Features" {
+		t.Errorf("FindGroup() = %v, want modified Groupings evaluated", got)
+	}
+}
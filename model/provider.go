@@ -0,0 +1,89 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Provider is a type alias representing the enumeration of supported git hosting services
+type Provider uint8
+
+const (
+	// GitHub or GitHub Enterprise
+	GitHub Provider = 1 << iota
+	// GitLab or self-managed GitLab
+	GitLab Provider = 1 << iota
+	// Gitea or Forgejo, which share a common API
+	Gitea Provider = 1 << iota
+	// BitbucketServer is Bitbucket Server or Bitbucket Data Center (not Bitbucket Cloud)
+	BitbucketServer Provider = 1 << iota
+)
+
+// MarshalJSON converts Provider into a string representation sufficient for JSON
+func (p *Provider) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte(""), nil
+	}
+
+	return []byte(fmt.Sprintf("%q", p.String())), nil
+}
+
+// UnmarshalJSON converts a JSON formatted character array into Provider
+func (p *Provider) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(b)), `"`)
+	switch strings.ToLower(s) {
+	case "github", "ghe":
+		*p = GitHub
+	case "gitlab":
+		*p = GitLab
+	case "gitea", "forgejo":
+		*p = Gitea
+	case "bitbucket-server", "bitbucket-data-center", "bitbucket-datacenter", "stash":
+		*p = BitbucketServer
+	default:
+		return fmt.Errorf("unknown provider %q", s)
+	}
+	return nil
+}
+
+func (p *Provider) UnmarshalYAML(b []byte) error {
+	return p.UnmarshalJSON(b)
+}
+
+func (p *Provider) MarshalYAML() ([]byte, error) {
+	return p.MarshalJSON()
+}
+
+// String displays a human readable representation of the Provider values
+func (p Provider) String() string {
+	switch p {
+	case GitLab:
+		return "gitlab"
+	case Gitea:
+		return "gitea"
+	case BitbucketServer:
+		return "bitbucket-server"
+	case GitHub:
+		fallthrough
+	default:
+		return "github"
+	}
+}
+
+func (p Provider) Ptr() *Provider {
+	return &p
+}
@@ -0,0 +1,100 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProvider_String(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Provider
+		want string
+	}{
+		{"GitHub.String()", GitHub, "github"},
+		{"GitLab.String()", GitLab, "gitlab"},
+		{"Gitea.String()", Gitea, "gitea"},
+		{"BitbucketServer.String()", BitbucketServer, "bitbucket-server"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    Provider
+		wantErr bool
+	}{
+		{"unmarshal github", []byte(`"github"`), GitHub, false},
+		{"unmarshal gitlab", []byte(`"gitlab"`), GitLab, false},
+		{"unmarshal gitea", []byte(`"gitea"`), Gitea, false},
+		{"unmarshal forgejo", []byte(`"forgejo"`), Gitea, false},
+		{"unmarshal bitbucket-server", []byte(`"bitbucket-server"`), BitbucketServer, false},
+		{"unmarshal bitbucket-data-center", []byte(`"bitbucket-data-center"`), BitbucketServer, false},
+		{"unmarshal bitbucket cloud", []byte(`"bitbucket"`), 0, true},
+		{"unmarshal mixed case", []byte(`"GitLab"`), GitLab, false},
+		{"unmarshal unquoted (yaml)", []byte(`gitlab`), GitLab, false},
+		{"unmarshal unknown", []byte(`"svn"`), 0, true},
+		{"unmarshal empty", []byte(""), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p Provider
+			err := p.UnmarshalJSON(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && p != tt.want {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestProvider_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name string
+		p    *Provider
+		want []byte
+	}{
+		{"marshal github", GitHub.Ptr(), []byte(`"github"`)},
+		{"marshal gitlab", GitLab.Ptr(), []byte(`"gitlab"`)},
+		{"marshal gitea", Gitea.Ptr(), []byte(`"gitea"`)},
+		{"marshal bitbucket-server", BitbucketServer.Ptr(), []byte(`"bitbucket-server"`)},
+		{"marshal nil instance succeeds with empty result", nil, []byte("")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.p.MarshalJSON()
+			if err != nil {
+				t.Errorf("MarshalJSON() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MarshalJSON() got = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
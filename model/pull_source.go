@@ -0,0 +1,77 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PullSource is a type alias representing the enumeration of methods by which a commit's pull request was identified
+type PullSource uint8
+
+const (
+	// PullFromAssociation identifies a pull request via the hosting service's commit-to-pull-request association API
+	PullFromAssociation PullSource = 1 << iota
+	// PullFromTitle identifies a pull request from a reference (e.g. "#123") in the commit title
+	PullFromTitle PullSource = 1 << iota
+)
+
+// MarshalJSON converts PullSource into a string representation sufficient for JSON
+func (p *PullSource) MarshalJSON() ([]byte, error) {
+	if p == nil {
+		return []byte(""), nil
+	}
+
+	return []byte(fmt.Sprintf("%q", p.String())), nil
+}
+
+// UnmarshalJSON converts a JSON formatted character array into PullSource
+func (p *PullSource) UnmarshalJSON(b []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(b)), `"`)
+	switch strings.ToLower(s) {
+	case "association":
+		*p = PullFromAssociation
+	case "title":
+		*p = PullFromTitle
+	default:
+		return fmt.Errorf("unknown pull source %q", s)
+	}
+	return nil
+}
+
+func (p *PullSource) UnmarshalYAML(b []byte) error {
+	return p.UnmarshalJSON(b)
+}
+
+func (p *PullSource) MarshalYAML() ([]byte, error) {
+	return p.MarshalJSON()
+}
+
+// String displays a human readable representation of the PullSource values
+func (p PullSource) String() string {
+	switch p {
+	case PullFromAssociation:
+		return "association"
+	case PullFromTitle:
+		return "title"
+	default:
+		return ""
+	}
+}
+
+func (p PullSource) Ptr() *PullSource {
+	return &p
+}
@@ -0,0 +1,81 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPullSource_String(t *testing.T) {
+	tests := []struct {
+		name string
+		p    PullSource
+		want string
+	}{
+		{"PullFromAssociation.String()", PullFromAssociation, "association"},
+		{"PullFromTitle.String()", PullFromTitle, "title"},
+		{"zero value", PullSource(0), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.p.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPullSource_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		b       []byte
+		want    PullSource
+		wantErr bool
+	}{
+		{"unmarshal association", []byte(`"association"`), PullFromAssociation, false},
+		{"unmarshal title", []byte(`"title"`), PullFromTitle, false},
+		{"unmarshal unknown", []byte(`"regex"`), 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p PullSource
+			err := p.UnmarshalJSON(tt.b)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && p != tt.want {
+				t.Errorf("UnmarshalJSON() got = %v, want %v", p, tt.want)
+			}
+		})
+	}
+}
+
+func TestChangeItem_PullSource_json(t *testing.T) {
+	ci := ChangeItem{PullSourceRaw: PullFromAssociation.Ptr()}
+	b, err := json.Marshal(&ci)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	var roundTrip ChangeItem
+	if err := json.Unmarshal(b, &roundTrip); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	if roundTrip.PullSource() != PullFromAssociation {
+		t.Errorf("PullSource() = %v, want %v", roundTrip.PullSource(), PullFromAssociation)
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import "time"
+
+// RefType is the kind of reference named by a revision
+type RefType uint8
+
+const (
+	// CommitRef is a revision which doesn't name a branch or tag, such as a SHA or HEAD~1
+	CommitRef RefType = 1 << iota
+	// BranchRef is a revision naming a branch
+	BranchRef RefType = 1 << iota
+	// TagRef is a revision naming a tag, which may be lightweight or annotated
+	TagRef RefType = 1 << iota
+)
+
+// String displays a human readable representation of the RefType values
+func (r RefType) String() string {
+	switch r {
+	case BranchRef:
+		return "branch"
+	case TagRef:
+		return "tag"
+	case CommitRef:
+		fallthrough
+	default:
+		return "commit"
+	}
+}
+
+// RefInfo describes a revision bounding a changelog
+type RefInfo struct {
+	// Name is the revision as provided, e.g. v1.2.0, main or HEAD~1
+	Name string
+
+	// Type is the kind of reference named by the revision
+	Type RefType
+
+	// SHA is the full hash of the commit which the revision resolves to
+	SHA string
+
+	// Date is when an annotated tag was created, otherwise the date of the commit
+	Date time.Time
+
+	// Tagger is the name of an annotated tag's creator
+	Tagger string
+
+	// TaggerEmail is the email address of an annotated tag's creator
+	TaggerEmail string
+
+	// Annotation is the message of an annotated tag
+	Annotation string
+}
+
+// IsTag determines whether the revision names a tag
+func (r *RefInfo) IsTag() bool {
+	return r.Type == TagRef
+}
+
+// IsAnnotated determines whether the revision names an annotated tag, which has a tagger and annotation of its own
+func (r *RefInfo) IsAnnotated() bool {
+	return r.IsTag() && r.Tagger != ""
+}
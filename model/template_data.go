@@ -24,6 +24,21 @@ type TemplateData struct {
 	PatchURL        string
 	CompareURL      string
 	Grouped         []TemplateGroup
+
+	// Ref describes Version, when the store can resolve it
+	Ref *RefInfo
+
+	// PreviousRef describes PreviousVersion, when the store can resolve it
+	PreviousRef *RefInfo
+
+	// NextVersion names the release of Items, bumping the previous release tag by the most significant of their changes
+	// (e.g. v1.3.0 following v1.2.4 when a feature was added). It's empty when there are no items.
+	NextVersion string
+}
+
+// History is the structure bound to history templates, holding the data of each release from the newest to the oldest
+type History struct {
+	Releases []*TemplateData
 }
 
 // TemplateGroup allows for data to be grouped in order as defined by user config
@@ -0,0 +1,212 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URLTemplates define the locations of a repository's pages on a git hosting service.
+// Templates may reference the placeholders {base}, {owner} and {repo}, as well as
+// {from} and {to} (Compare, Diff, Patch), {sha} (Commit) or {id} (Pull). The latter are
+// escaped for their position, within the path or the query string.
+type URLTemplates struct {
+	Compare string `json:"compare,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	Patch   string `json:"patch,omitempty"`
+	Commit  string `json:"commit,omitempty"`
+	Pull    string `json:"pull,omitempty"`
+}
+
+// URLConfig selects a built-in URL scheme, optionally overriding individual templates
+type URLConfig struct {
+	// Scheme is one of the built-in URL schemes; defaults to the scheme of the configured Provider
+	Scheme string `json:"scheme,omitempty"`
+
+	// Base is the web location of the hosting service; defaults to Enterprise, then the scheme's public host
+	Base string `json:"base,omitempty"`
+
+	// URLTemplates override those of the Scheme when non-empty
+	URLTemplates `json:",inline" yaml:",inline"`
+}
+
+type urlScheme struct {
+	base      string
+	templates URLTemplates
+}
+
+// urlSchemes are the built-in URL layouts of supported hosting services, keyed by scheme name
+var urlSchemes = map[string]urlScheme{
+	"github": {
+		base: "https://github.com",
+		templates: URLTemplates{
+			Compare: "{base}/{owner}/{repo}/compare/{from}...{to}",
+			Diff:    "{base}/{owner}/{repo}/compare/{from}...{to}.diff",
+			Patch:   "{base}/{owner}/{repo}/compare/{from}...{to}.patch",
+			Commit:  "{base}/{owner}/{repo}/commit/{sha}",
+			Pull:    "{base}/{owner}/{repo}/pull/{id}",
+		},
+	},
+	"gitlab": {
+		base: "https://gitlab.com",
+		templates: URLTemplates{
+			Compare: "{base}/{owner}/{repo}/-/compare/{from}...{to}",
+			Diff:    "{base}/{owner}/{repo}/-/compare/{from}...{to}.diff",
+			Patch:   "{base}/{owner}/{repo}/-/compare/{from}...{to}.patch",
+			Commit:  "{base}/{owner}/{repo}/-/commit/{sha}",
+			Pull:    "{base}/{owner}/{repo}/-/merge_requests/{id}",
+		},
+	},
+	"gitea": {
+		templates: URLTemplates{
+			Compare: "{base}/{owner}/{repo}/compare/{from}...{to}",
+			Diff:    "{base}/{owner}/{repo}/compare/{from}...{to}.diff",
+			Patch:   "{base}/{owner}/{repo}/compare/{from}...{to}.patch",
+			Commit:  "{base}/{owner}/{repo}/commit/{sha}",
+			Pull:    "{base}/{owner}/{repo}/pulls/{id}",
+		},
+	},
+	"bitbucket": {
+		base: "https://bitbucket.org",
+		templates: URLTemplates{
+			Compare: "{base}/{owner}/{repo}/branches/compare/{to}%0D{from}",
+			Diff:    "{base}/{owner}/{repo}/branches/compare/{to}%0D{from}#diff",
+			Commit:  "{base}/{owner}/{repo}/commits/{sha}",
+			Pull:    "{base}/{owner}/{repo}/pull-requests/{id}",
+		},
+	},
+	"bitbucket-server": {
+		templates: URLTemplates{
+			Compare: "{base}/projects/{owner}/repos/{repo}/compare/commits?sourceBranch={to}&targetBranch={from}",
+			Diff:    "{base}/projects/{owner}/repos/{repo}/compare/diff?sourceBranch={to}&targetBranch={from}",
+			Patch:   "{base}/rest/api/latest/projects/{owner}/repos/{repo}/patch?since={from}&until={to}",
+			Commit:  "{base}/projects/{owner}/repos/{repo}/commits/{sha}",
+			Pull:    "{base}/projects/{owner}/repos/{repo}/pull-requests/{id}",
+		},
+	},
+}
+
+// URLBuilder creates links to commits, pull requests and comparisons for a repository
+type URLBuilder struct {
+	base      string
+	owner     string
+	repo      string
+	templates URLTemplates
+}
+
+// NewURLBuilder creates a URLBuilder for owner/repo from a built-in scheme, with non-empty overrides replacing the scheme's templates.
+// An empty base falls back to the scheme's public host.
+func NewURLBuilder(scheme string, base string, owner string, repo string, overrides URLTemplates) (*URLBuilder, error) {
+	s, ok := urlSchemes[strings.ToLower(scheme)]
+	if !ok {
+		return nil, fmt.Errorf("unknown url scheme %q", scheme)
+	}
+
+	if base == "" {
+		base = s.base
+	}
+	if base == "" {
+		return nil, fmt.Errorf("url scheme %q requires a base url", scheme)
+	}
+	if _, err := url.Parse(base); err != nil {
+		return nil, err
+	}
+
+	templates := s.templates
+	override := func(target *string, value string) {
+		if value != "" {
+			*target = value
+		}
+	}
+	override(&templates.Compare, overrides.Compare)
+	override(&templates.Diff, overrides.Diff)
+	override(&templates.Patch, overrides.Patch)
+	override(&templates.Commit, overrides.Commit)
+	override(&templates.Pull, overrides.Pull)
+
+	return &URLBuilder{
+		base:      strings.TrimSuffix(base, "/"),
+		owner:     owner,
+		repo:      repo,
+		templates: templates,
+	}, nil
+}
+
+// expand substitutes the placeholders of template, escaping the values of pairs for a query string when they follow "?"
+// and for a path otherwise
+func (b *URLBuilder) expand(template string, pairs ...string) string {
+	if template == "" {
+		return ""
+	}
+	replace := func(s string, escape func(string) string) string {
+		escaped := make([]string, 0, len(pairs)+6)
+		for i := 0; i+1 < len(pairs); i += 2 {
+			escaped = append(escaped, pairs[i], escape(pairs[i+1]))
+		}
+		escaped = append(escaped, "{base}", b.base, "{owner}", b.owner, "{repo}", b.repo)
+		return strings.NewReplacer(escaped...).Replace(s)
+	}
+
+	path, query, found := strings.Cut(template, "?")
+	if !found {
+		return replace(path, escapePath)
+	}
+	return replace(path, escapePath) + "?" + replace(query, url.QueryEscape)
+}
+
+// escapePath escapes each segment of value, keeping the slashes of names such as release/1.x
+func escapePath(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// CompareURL links to a comparison of from and to
+func (b *URLBuilder) CompareURL(from string, to string) string {
+	return b.expand(b.templates.Compare, "{from}", from, "{to}", to)
+}
+
+// DiffURL links to the diff between from and to
+func (b *URLBuilder) DiffURL(from string, to string) string {
+	return b.expand(b.templates.Diff, "{from}", from, "{to}", to)
+}
+
+// PatchURL links to a patch file generated from from and to
+func (b *URLBuilder) PatchURL(from string, to string) string {
+	return b.expand(b.templates.Patch, "{from}", from, "{to}", to)
+}
+
+// CommitURL links to the commit identified by sha
+func (b *URLBuilder) CommitURL(sha string) string {
+	return b.expand(b.templates.Commit, "{sha}", sha)
+}
+
+// PullURL links to the pull (or merge) request identified by id
+func (b *URLBuilder) PullURL(id string) string {
+	return b.expand(b.templates.Pull, "{id}", id)
+}
+
+// GitURLs creates the compare, diff and patch locations for from and to
+func (b *URLBuilder) GitURLs(from string, to string) *GitURLs {
+	return &GitURLs{
+		CompareURL: b.CompareURL(from, to),
+		DiffURL:    b.DiffURL(from, to),
+		PatchURL:   b.PatchURL(from, to),
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewURLBuilder(t *testing.T) {
+	type want struct {
+		compare string
+		diff    string
+		patch   string
+		commit  string
+		pull    string
+	}
+	tests := []struct {
+		name      string
+		scheme    string
+		base      string
+		owner     string
+		overrides URLTemplates
+		want      want
+		wantErr   bool
+	}{
+		{"github", "github", "", "jimschubert", URLTemplates{}, want{
+			compare: "https://github.com/jimschubert/changelog/compare/v1...v2",
+			diff:    "https://github.com/jimschubert/changelog/compare/v1...v2.diff",
+			patch:   "https://github.com/jimschubert/changelog/compare/v1...v2.patch",
+			commit:  "https://github.com/jimschubert/changelog/commit/abc",
+			pull:    "https://github.com/jimschubert/changelog/pull/12",
+		}, false},
+		{"gitlab with trailing slash base", "gitlab", "https://gitlab.example.com/", "group/sub", URLTemplates{}, want{
+			compare: "https://gitlab.example.com/group/sub/changelog/-/compare/v1...v2",
+			diff:    "https://gitlab.example.com/group/sub/changelog/-/compare/v1...v2.diff",
+			patch:   "https://gitlab.example.com/group/sub/changelog/-/compare/v1...v2.patch",
+			commit:  "https://gitlab.example.com/group/sub/changelog/-/commit/abc",
+			pull:    "https://gitlab.example.com/group/sub/changelog/-/merge_requests/12",
+		}, false},
+		{"gitea", "Gitea", "https://codeberg.org", "jimschubert", URLTemplates{}, want{
+			compare: "https://codeberg.org/jimschubert/changelog/compare/v1...v2",
+			diff:    "https://codeberg.org/jimschubert/changelog/compare/v1...v2.diff",
+			patch:   "https://codeberg.org/jimschubert/changelog/compare/v1...v2.patch",
+			commit:  "https://codeberg.org/jimschubert/changelog/commit/abc",
+			pull:    "https://codeberg.org/jimschubert/changelog/pulls/12",
+		}, false},
+		{"bitbucket", "bitbucket", "", "jimschubert", URLTemplates{}, want{
+			compare: "https://bitbucket.org/jimschubert/changelog/branches/compare/v2%0Dv1",
+			diff:    "https://bitbucket.org/jimschubert/changelog/branches/compare/v2%0Dv1#diff",
+			patch:   "",
+			commit:  "https://bitbucket.org/jimschubert/changelog/commits/abc",
+			pull:    "https://bitbucket.org/jimschubert/changelog/pull-requests/12",
+		}, false},
+		{"bitbucket server", "bitbucket-server", "https://git.example.com", "PROJ", URLTemplates{}, want{
+			compare: "https://git.example.com/projects/PROJ/repos/changelog/compare/commits?sourceBranch=v2&targetBranch=v1",
+			diff:    "https://git.example.com/projects/PROJ/repos/changelog/compare/diff?sourceBranch=v2&targetBranch=v1",
+			patch:   "https://git.example.com/rest/api/latest/projects/PROJ/repos/changelog/patch?since=v1&until=v2",
+			commit:  "https://git.example.com/projects/PROJ/repos/changelog/commits/abc",
+			pull:    "https://git.example.com/projects/PROJ/repos/changelog/pull-requests/12",
+		}, false},
+		{"user-supplied templates override scheme", "github", "https://mirror.example.com", "jimschubert", URLTemplates{
+			Commit: "{base}/cgit/{repo}.git/commit/?id={sha}",
+			Pull:   "https://review.example.com/{owner}/{repo}/{id}",
+		}, want{
+			compare: "https://mirror.example.com/jimschubert/changelog/compare/v1...v2",
+			diff:    "https://mirror.example.com/jimschubert/changelog/compare/v1...v2.diff",
+			patch:   "https://mirror.example.com/jimschubert/changelog/compare/v1...v2.patch",
+			commit:  "https://mirror.example.com/cgit/changelog.git/commit/?id=abc",
+			pull:    "https://review.example.com/jimschubert/changelog/12",
+		}, false},
+		{"unknown scheme", "svn", "https://svn.example.com", "jimschubert", URLTemplates{}, want{}, true},
+		{"scheme without public host requires base", "gitea", "", "jimschubert", URLTemplates{}, want{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewURLBuilder(tt.scheme, tt.base, tt.owner, "changelog", tt.overrides)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want.compare, b.CompareURL("v1", "v2"))
+			assert.Equal(t, tt.want.diff, b.DiffURL("v1", "v2"))
+			assert.Equal(t, tt.want.patch, b.PatchURL("v1", "v2"))
+			assert.Equal(t, tt.want.commit, b.CommitURL("abc"))
+			assert.Equal(t, tt.want.pull, b.PullURL("12"))
+		})
+	}
+}
+
+func TestURLBuilder_escaping(t *testing.T) {
+	tests := []struct {
+		name        string
+		scheme      string
+		wantCompare string
+		wantPatch   string
+	}{
+		{"path keeps slashes", "github",
+			"https://git.example.com/o/r/compare/release/1.x...feature/a%23b%3Fc&d",
+			"https://git.example.com/o/r/compare/release/1.x...feature/a%23b%3Fc&d.patch"},
+		{"query string", "bitbucket-server",
+			"https://git.example.com/projects/o/repos/r/compare/commits?sourceBranch=feature%2Fa%23b%3Fc%26d&targetBranch=release%2F1.x",
+			"https://git.example.com/rest/api/latest/projects/o/repos/r/patch?since=release%2F1.x&until=feature%2Fa%23b%3Fc%26d"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := NewURLBuilder(tt.scheme, "https://git.example.com", "o", "r", URLTemplates{})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCompare, b.CompareURL("release/1.x", "feature/a#b?c&d"))
+			assert.Equal(t, tt.wantPatch, b.PatchURL("release/1.x", "feature/a#b?c&d"))
+		})
+	}
+}
+
+func TestConfig_URLBuilder(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     Config
+		wantCommit string
+	}{
+		{"defaults to github",
+			Config{Owner: "o", Repo: "r"},
+			"https://github.com/o/r/commit/abc"},
+		{"github enterprise",
+			Config{Owner: "o", Repo: "r", Enterprise: p("https://ghe.example.com/api/v3/")},
+			"https://ghe.example.com/o/r/commit/abc"},
+		{"follows provider",
+			Config{Owner: "o", Repo: "r", Provider: GitLab.Ptr()},
+			"https://gitlab.com/o/r/-/commit/abc"},
+		{"mirror scheme ignores provider enterprise url",
+			Config{Owner: "o", Repo: "r", Enterprise: p("https://ghe.example.com"), URLs: &URLConfig{Scheme: "gitlab"}},
+			"https://gitlab.com/o/r/-/commit/abc"},
+		{"mirror scheme and base",
+			Config{Owner: "o", Repo: "r", URLs: &URLConfig{Scheme: "gitea", Base: "https://mirror.example.com"}},
+			"https://mirror.example.com/o/r/commit/abc"},
+		{"template override only",
+			Config{Owner: "o", Repo: "r", URLs: &URLConfig{URLTemplates: URLTemplates{Commit: "{base}/{repo}/c/{sha}"}}},
+			"https://github.com/r/c/abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := tt.config.URLBuilder()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantCommit, b.CommitURL("abc"))
+		})
+	}
+}
+
+func TestConfig_Load_urls(t *testing.T) {
+	for _, ext := range []string{"json", "yaml"} {
+		t.Run(ext, func(t *testing.T) {
+			data := `{"urls": {"scheme": "gitlab", "base": "https://mirror.example.com", "pull": "{base}/{owner}/{repo}/-/merge_requests/{id}/diffs"}}`
+			if ext == "yaml" {
+				data = "urls:\n  scheme: gitlab\n  base: https://mirror.example.com\n  pull: '{base}/{owner}/{repo}/-/merge_requests/{id}/diffs'\n"
+			}
+			location, cleanup := createTempConfig(t, data, ext)
+			defer cleanup()
+
+			c := &Config{Owner: "o", Repo: "r"}
+			assert.NoError(t, c.Load(location))
+			if assert.NotNil(t, c.URLs) {
+				assert.Equal(t, "gitlab", c.URLs.Scheme)
+				assert.Equal(t, "https://mirror.example.com", c.URLs.Base)
+				assert.Equal(t, "{base}/{owner}/{repo}/-/merge_requests/{id}/diffs", c.URLs.Pull)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// see https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+var versionPattern = regexp.MustCompile(`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+	`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+
+// Version is a semantic version, e.g. 1.2.3-rc.1+build.5
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+	Build      string
+}
+
+// ParseVersion parses a semantic version, optionally prefixed with "v"
+func ParseVersion(s string) (*Version, error) {
+	match := versionPattern.FindStringSubmatch(s)
+	if match == nil {
+		return nil, fmt.Errorf("invalid semantic version %q", s)
+	}
+
+	v := &Version{Prerelease: match[4], Build: match[5]}
+	for i, part := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		n, err := strconv.Atoi(match[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid semantic version %q: %w", s, err)
+		}
+		*part = n
+	}
+	return v, nil
+}
+
+// IsPrerelease determines whether the version has prerelease identifiers, e.g. 1.0.0-beta.1
+func (v *Version) IsPrerelease() bool {
+	return v.Prerelease != ""
+}
+
+// Compare returns -1, 0 or +1 as v has lower, equal or higher precedence than other. Build metadata doesn't affect precedence.
+func (v *Version) Compare(other *Version) int {
+	if c := cmp.Compare(v.Major, other.Major); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Minor, other.Minor); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(v.Patch, other.Patch); c != 0 {
+		return c
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// Bump increments the version by b, resetting less significant components. A prerelease is first released at the
+// version it precedes when b allows, so 2.0.0-rc.1 bumped by Minor is 2.0.0 while 1.2.3-rc.1 bumped by Minor is 1.3.0.
+// Prerelease identifiers and build metadata are dropped.
+func (v *Version) Bump(b Bump) *Version {
+	next := &Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch}
+	switch b {
+	case Major:
+		if !v.IsPrerelease() || v.Minor != 0 || v.Patch != 0 {
+			next.Major++
+		}
+		next.Minor, next.Patch = 0, 0
+	case Minor:
+		if !v.IsPrerelease() || v.Patch != 0 {
+			next.Minor++
+		}
+		next.Patch = 0
+	case Patch:
+		if !v.IsPrerelease() {
+			next.Patch++
+		}
+	}
+	return next
+}
+
+// String displays the version without a "v" prefix
+func (v *Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// comparePrerelease orders prerelease identifiers, where a release (no identifiers) has higher precedence than any prerelease
+func comparePrerelease(a string, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range min(len(as), len(bs)) {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			// numeric identifiers have lower precedence than alphanumeric identifiers
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package model
+
+import (
+	"testing"
+)
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{"release", "1.2.3", "1.2.3", false},
+		{"v prefix", "v1.2.3", "1.2.3", false},
+		{"prerelease and build", "v2.0.0-rc.1+build.5", "2.0.0-rc.1+build.5", false},
+		{"missing patch", "v1.2", "", true},
+		{"leading zero", "v1.02.3", "", true},
+		{"other prefix", "api/v1.2.3", "", true},
+		{"branch name", "main", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseVersion(tt.s)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseVersion() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("ParseVersion() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	// ordered by increasing precedence, as in https://semver.org/#spec-item-11
+	ordered := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1",
+		"1.0.0", "1.0.1", "1.2.0", "1.10.0", "2.0.0",
+	}
+	for i := range ordered {
+		for j := range ordered {
+			a, _ := ParseVersion(ordered[i])
+			b, _ := ParseVersion(ordered[j])
+			want := 0
+			switch {
+			case i < j:
+				want = -1
+			case i > j:
+				want = 1
+			}
+			if got := a.Compare(b); got != want {
+				t.Errorf("%s.Compare(%s) = %d, want %d", a, b, got, want)
+			}
+		}
+	}
+
+	a, _ := ParseVersion("1.0.0+build.1")
+	b, _ := ParseVersion("1.0.0+build.2")
+	if got := a.Compare(b); got != 0 {
+		t.Errorf("Compare() = %d, build metadata doesn't affect precedence", got)
+	}
+}
+
+func TestVersion_Bump(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		b    Bump
+		want string
+	}{
+		{"patch", "1.2.3", Patch, "1.2.4"},
+		{"minor resets patch", "1.2.3", Minor, "1.3.0"},
+		{"major resets minor and patch", "1.2.3", Major, "2.0.0"},
+		{"build metadata is dropped", "1.2.3+build.5", Patch, "1.2.4"},
+		{"prerelease patch is released", "1.2.3-rc.1", Patch, "1.2.3"},
+		{"prerelease minor is released", "1.3.0-rc.1", Minor, "1.3.0"},
+		{"prerelease patch bumped by minor", "1.2.3-rc.1", Minor, "1.3.0"},
+		{"prerelease major is released", "2.0.0-rc.1", Major, "2.0.0"},
+		{"prerelease minor bumped by major", "1.3.0-rc.1", Major, "2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := ParseVersion(tt.s)
+			if err != nil {
+				t.Fatalf("ParseVersion() error = %v", err)
+			}
+			if got := v.Bump(tt.b).String(); got != tt.want {
+				t.Errorf("Bump(%s) = %v, want %v", tt.b, got, tt.want)
+			}
+			if v.String() != tt.s {
+				t.Errorf("Bump(%s) modified the version to %v", tt.b, v)
+			}
+		})
+	}
+}
@@ -0,0 +1,312 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// bitbucketPageSize is the number of results requested per page of a Bitbucket Server paged API
+const bitbucketPageSize = 100
+
+type bitbucketServerService struct {
+	contextual *Contextual
+	config     *model.Config
+	api        *restClient
+}
+
+type bitbucketLinks struct {
+	Self []struct {
+		Href string `json:"href"`
+	} `json:"self"`
+}
+
+func (l bitbucketLinks) href() string {
+	if len(l.Self) == 0 {
+		return ""
+	}
+	return l.Self[0].Href
+}
+
+type bitbucketUser struct {
+	Name        string         `json:"name"`
+	DisplayName string         `json:"displayName"`
+	Links       bitbucketLinks `json:"links"`
+}
+
+type bitbucketCommit struct {
+	ID                 string        `json:"id"`
+	Message            string        `json:"message"`
+	Author             bitbucketUser `json:"author"`
+	AuthorTimestamp    int64         `json:"authorTimestamp"`
+	CommitterTimestamp int64         `json:"committerTimestamp"`
+	Parents            []struct {
+		ID string `json:"id"`
+	} `json:"parents"`
+}
+
+type bitbucketPullRequest struct {
+	ID     int    `json:"id"`
+	Title  string `json:"title"`
+	State  string `json:"state"`
+	Author struct {
+		User bitbucketUser `json:"user"`
+	} `json:"author"`
+	ClosedDate int64          `json:"closedDate"`
+	Links      bitbucketLinks `json:"links"`
+	Properties struct {
+		MergeCommit *struct {
+			ID string `json:"id"`
+		} `json:"mergeCommit"`
+	} `json:"properties"`
+}
+
+// bitbucketPage is the envelope of Bitbucket Server's paged APIs
+type bitbucketPage[T any] struct {
+	Values        []T  `json:"values"`
+	IsLastPage    bool `json:"isLastPage"`
+	NextPageStart int  `json:"nextPageStart"`
+}
+
+// NewBitbucketServerService creates a new Store for accessing commits from the Bitbucket Server (or Data Center) REST API hosted at
+// baseURL (e.g. https://bitbucket.example.com). The token is an HTTP access token or personal access token; an empty token allows
+// read access to public repositories only.
+func NewBitbucketServerService(baseURL string, token string, httpClient *http.Client) (Store, error) {
+	apiURL := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(apiURL, "/rest/api/1.0") {
+		apiURL += "/rest/api/1.0"
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+
+	api, err := newRestClient(apiURL, httpClient, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &bitbucketServerService{contextual: newContextual(nil), api: api}, nil
+}
+
+// WithClient is a no-op, as Bitbucket Server is queried via its REST API rather than a GitHub client
+func (s *bitbucketServerService) WithClient(client *github.Client) Store {
+	return s
+}
+
+// WithConfig applies a Config instance to the Store
+func (s *bitbucketServerService) WithConfig(config *model.Config) Store {
+	s.config = config
+	s.contextual = s.contextual.withConfig(config)
+	return s
+}
+
+// GetContextual returns the context wrapper for this service
+func (s *bitbucketServerService) GetContextual() *Contextual {
+	return s.contextual
+}
+
+// Changes queries the service for commits, yielding each as a ChangeItem
+func (s *bitbucketServerService) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	list := func(ctx *context.Context) ([]bitbucketCommit, error) {
+		return s.commitsInRange(ctx, from, to, s.config.GetMaxCommits())
+	}
+	urls := urlBuilder(s.config)
+	convert := func(commit bitbucketCommit, ctx *context.Context) (*model.ChangeItem, error) {
+		return s.convertToChangeItem(commit, urls, ctx)
+	}
+	return streamCommits(ctx, s.contextual, s.config.GetConcurrency(), list, convert)
+}
+
+// commitsInRange pages through commits reachable from to but not from, stopping once maximum commits are collected
+func (s *bitbucketServerService) commitsInRange(parentContext *context.Context, from string, to string, maximum int) ([]bitbucketCommit, error) {
+	commits := make([]bitbucketCommit, 0)
+	start := 0
+	lastPage := false
+	for len(commits) < maximum {
+		limit := min(bitbucketPageSize, maximum-len(commits))
+		query := url.Values{
+			"since": {from},
+			"until": {to},
+			"start": {strconv.Itoa(start)},
+			"limit": {strconv.Itoa(limit)},
+		}
+
+		pageContext, cancel := s.contextual.CreateContext(parentContext)
+		// see https://developer.atlassian.com/server/bitbucket/rest/v906/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-get
+		page := new(bitbucketPage[bitbucketCommit])
+		_, err := s.api.get(pageContext, s.repoPath("commits"), query, page)
+		cancel()
+		if err != nil {
+			return nil, compareError(err, from, to)
+		}
+
+		log.WithFields(log.Fields{"start": start, "count": len(page.Values)}).Debug("retrieved commit page")
+		commits = append(commits, page.Values...)
+		lastPage = page.IsLastPage || len(page.Values) == 0
+		if lastPage {
+			break
+		}
+		start = page.NextPageStart
+	}
+
+	if len(commits) > maximum {
+		commits = commits[:maximum]
+	}
+	if !lastPage {
+		// pages are ordered from the newest commit, so the oldest are omitted
+		warnTruncated(from, to, 0, maximum)
+	}
+	return commits, nil
+}
+
+// DefaultBranch queries the repository's default branch
+func (s *bitbucketServerService) DefaultBranch(ctx context.Context) (string, error) {
+	branchContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+
+	// see https://developer.atlassian.com/server/bitbucket/rest/v906/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-branches-default-get
+	branch := new(struct {
+		DisplayID string `json:"displayId"`
+	})
+	if _, err := s.api.get(branchContext, s.repoPath("branches/default"), nil, branch); err != nil {
+		return "", err
+	}
+	return branch.DisplayID, nil
+}
+
+// repoPath creates a path relative to the API for the configured repository, where owner is the project key (or ~user for personal repositories)
+func (s *bitbucketServerService) repoPath(resource string) string {
+	return "projects/" + url.PathEscape(s.config.Owner) + "/repos/" + url.PathEscape(s.config.Repo) + "/" + resource
+}
+
+func (s *bitbucketServerService) convertToChangeItem(commit bitbucketCommit, urls *model.URLBuilder, ctx *context.Context) (*model.ChangeItem, error) {
+	if len(commit.Parents) > 1 {
+		return nil, nil // Skip merge commits
+	}
+
+	title, _, _ := strings.Cut(commit.Message, "\n")
+	if s.config.ShouldExcludeByText(&title) {
+		return nil, nil
+	}
+
+	grouping := s.config.FindGroup(commit.Message)
+	if s.config.ShouldExcludeByText(grouping) {
+		return nil, nil
+	}
+
+	author := commit.Author.DisplayName
+	if author == "" {
+		author = commit.Author.Name
+	}
+	timestamp := commit.CommitterTimestamp
+	if timestamp == 0 {
+		timestamp = commit.AuthorTimestamp
+	}
+	date := time.UnixMilli(timestamp).UTC()
+
+	ci := &model.ChangeItem{
+		AuthorRaw:        &author,
+		CommitMessageRaw: &commit.Message,
+		DateRaw:          &date,
+		CommitHashRaw:    &commit.ID,
+		GroupRaw:         grouping,
+	}
+
+	if urls != nil {
+		commitURL := urls.CommitURL(ci.CommitHash())
+		ci.CommitURLRaw = &commitURL
+	}
+	if authorURL := commit.Author.Links.href(); authorURL != "" {
+		ci.AuthorURLRaw = &authorURL
+	}
+
+	pullRequest, exclude, err := s.shouldExcludeViaPullRequest(commit.ID, ctx)
+	if err != nil {
+		return nil, &CommitError{SHA: commit.ID, Err: fmt.Errorf("unable to query pull requests: %w", err)}
+	}
+	if exclude {
+		return nil, nil
+	}
+
+	if pullRequest != nil {
+		isPull := true
+		pullURL := pullRequest.Links.href()
+		if urls != nil {
+			pullURL = urls.PullURL(strconv.Itoa(pullRequest.ID))
+		}
+		authorURL := pullRequest.Author.User.Links.href()
+		ci.IsPullRaw = &isPull
+		ci.PullSourceRaw = model.PullFromAssociation.Ptr()
+		ci.PullURLRaw = &pullURL
+		ci.AuthorRaw = &pullRequest.Author.User.Name
+		ci.AuthorURLRaw = &authorURL
+
+		ci.PullNumberRaw = &pullRequest.ID
+		ci.PullTitleRaw = &pullRequest.Title
+		ci.PullAuthorRaw = &pullRequest.Author.User.Name
+		ci.PullAuthorURLRaw = &authorURL
+		if pullRequest.ClosedDate > 0 {
+			mergedAt := time.UnixMilli(pullRequest.ClosedDate).UTC()
+			ci.MergedAtRaw = &mergedAt
+		}
+		if pullRequest.Properties.MergeCommit != nil {
+			ci.MergeCommitRaw = &pullRequest.Properties.MergeCommit.ID
+		}
+	}
+
+	return ci, nil
+}
+
+// shouldExcludeViaPullRequest finds the pull request which merged sha, evaluating its title against exclusion rules.
+// Bitbucket Server pull requests have no labels, so the title is the only pull request attribute considered. Commits
+// associated only with declined or open pull requests are treated as direct commits.
+func (s *bitbucketServerService) shouldExcludeViaPullRequest(sha string, parent *context.Context) (*bitbucketPullRequest, bool, error) {
+	timeout, cancel := s.contextual.CreateContext(parent)
+	defer cancel()
+
+	// see https://developer.atlassian.com/server/bitbucket/rest/v906/api-group-repository/#api-api-latest-projects-projectkey-repos-repositoryslug-commits-commitid-pull-requests-get
+	page := new(bitbucketPage[bitbucketPullRequest])
+	query := url.Values{"limit": {strconv.Itoa(bitbucketPageSize)}}
+	if _, err := s.api.get(timeout, s.repoPath("commits/"+url.PathEscape(sha)+"/pull-requests"), query, page); err != nil {
+		return nil, false, err
+	}
+
+	var pullRequest *bitbucketPullRequest
+	for i := range page.Values {
+		if page.Values[i].State == "MERGED" {
+			pullRequest = &page.Values[i]
+			break
+		}
+	}
+	if pullRequest == nil {
+		return nil, false, nil
+	}
+
+	return pullRequest, s.config.ShouldExcludeByText(&pullRequest.Title), nil
+}
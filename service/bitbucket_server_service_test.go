@@ -0,0 +1,181 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// newBitbucketServer is a stand-in for the Bitbucket Server REST API serving five commits of PROJ/project over two pages
+func newBitbucketServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newAPIServer(t, "Authorization", "Bearer secret", map[string]http.HandlerFunc{
+		"/rest/api/1.0/projects/PROJ/repos/project/commits": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "v1.0.0", r.URL.Query().Get("since"))
+			assert.Equal(t, "v1.1.0", r.URL.Query().Get("until"))
+			switch r.URL.Query().Get("start") {
+			case "0":
+				_, _ = w.Write([]byte(`{"size": 4, "limit": 4, "start": 0, "isLastPage": false, "nextPageStart": 4, "values": [
+					{"id": "5555555555555555555555555555555555555555", "message": "Experimental gizmo",
+					 "author": {"name": "jim", "displayName": "Jim Schubert"}, "committerTimestamp": 1767693600000,
+					 "parents": [{"id": "4444444444444444444444444444444444444444"}]},
+					{"id": "4444444444444444444444444444444444444444", "message": "Merge pull request #4 in PROJ/project from support to main",
+					 "author": {"name": "jim", "displayName": "Jim Schubert"}, "committerTimestamp": 1767607200000,
+					 "parents": [{"id": "1111111111111111111111111111111111111111"}, {"id": "3333333333333333333333333333333333333333"}]},
+					{"id": "3333333333333333333333333333333333333333", "message": "Drop the draft",
+					 "author": {"name": "jim", "displayName": "Jim Schubert"}, "committerTimestamp": 1767520800000,
+					 "parents": [{"id": "2222222222222222222222222222222222222222"}]},
+					{"id": "2222222222222222222222222222222222222222", "message": "Support Bitbucket",
+					 "author": {"name": "jim", "displayName": "Jim Schubert"}, "committerTimestamp": 1767434400000,
+					 "parents": [{"id": "1111111111111111111111111111111111111111"}]}
+				]}`))
+			case "4":
+				_, _ = w.Write([]byte(`{"size": 1, "limit": 4, "start": 4, "isLastPage": true, "values": [
+					{"id": "1111111111111111111111111111111111111111", "message": "Tidy the readme",
+					 "author": {"name": "unknown@example.com"}, "authorTimestamp": 1767348000000,
+					 "parents": [{"id": "0000000000000000000000000000000000000000"}]}
+				]}`))
+			default:
+				t.Errorf("unexpected page start %q", r.URL.Query().Get("start"))
+				http.NotFound(w, r)
+			}
+		},
+		"/rest/api/1.0/projects/PROJ/repos/project/commits/1111111111111111111111111111111111111111/pull-requests": respond(`{"size": 0, "isLastPage": true, "values": []}`),
+		"/rest/api/1.0/projects/PROJ/repos/project/commits/2222222222222222222222222222222222222222/pull-requests": respond(`{"size": 2, "isLastPage": true, "values": [
+			{"id": 3, "title": "Support Bitbucket (draft)", "state": "DECLINED", "closedDate": 1767607200000, "author": {"user": {"name": "someone"}},
+			 "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/project/pull-requests/3"}]}},
+			{"id": 4, "title": "Support Bitbucket", "state": "MERGED", "closedDate": 1767693600000,
+			 "properties": {"mergeCommit": {"id": "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"}},
+			 "author": {"user": {"name": "octocat", "displayName": "Octo Cat", "links": {"self": [{"href": "https://bitbucket.example.com/users/octocat"}]}}},
+			 "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/project/pull-requests/4"}]}}
+		]}`),
+		"/rest/api/1.0/projects/PROJ/repos/project/commits/3333333333333333333333333333333333333333/pull-requests": respond(`{"size": 1, "isLastPage": true, "values": [
+			{"id": 5, "title": "Drop the draft", "state": "DECLINED", "closedDate": 1767780000000, "author": {"user": {"name": "octocat"}},
+			 "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/project/pull-requests/5"}]}}
+		]}`),
+		"/rest/api/1.0/projects/PROJ/repos/project/commits/5555555555555555555555555555555555555555/pull-requests": respond(`{"size": 1, "isLastPage": true, "values": [
+			{"id": 6, "title": "Experimental gizmo (do not release)", "state": "MERGED", "author": {"user": {"name": "octocat"}},
+			 "links": {"self": [{"href": "https://bitbucket.example.com/projects/PROJ/repos/project/pull-requests/6"}]}}
+		]}`),
+	})
+}
+
+func Test_bitbucketServerService_Changes(t *testing.T) {
+	server := newBitbucketServer(t)
+	enterprise := "https://bitbucket.example.com"
+	config := &model.Config{
+		Owner:      "PROJ",
+		Repo:       "project",
+		Provider:   model.BitbucketServer.Ptr(),
+		Enterprise: &enterprise,
+		Exclude:    []string{"do not release"},
+		Groupings:  []model.Grouping{{Name: "Features", Patterns: []string{"^Support"}}},
+	}
+
+	store, err := NewBitbucketServerService(server.URL, "secret", server.Client())
+	assert.NoError(t, err)
+
+	items, err := collect(t, store.WithConfig(config), "v1.0.0", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Len(t, items, 3, "merge commits and commits from excluded pull requests are skipped")
+
+	byHash := make(map[string]model.ChangeItem)
+	for _, item := range items {
+		byHash[item.CommitHash()] = item
+	}
+	assert.NotContains(t, byHash, "4444444444444444444444444444444444444444", "merge commit")
+	assert.NotContains(t, byHash, "5555555555555555555555555555555555555555", "pull request title matches an exclusion")
+
+	direct := byHash["1111111111111111111111111111111111111111"]
+	assert.False(t, direct.IsPull())
+	assert.Equal(t, "unknown@example.com", direct.Author(), "authors without a display name fall back to their name")
+	assert.Equal(t, "2026-01-02T10:00:00Z", direct.Date().Format("2006-01-02T15:04:05Z07:00"), "the author timestamp is used without a committer timestamp")
+	assert.Equal(t, "https://bitbucket.example.com/projects/PROJ/repos/project/commits/1111111111111111111111111111111111111111", direct.CommitURL())
+	assert.Empty(t, direct.Group())
+
+	merged := byHash["2222222222222222222222222222222222222222"]
+	assert.True(t, merged.IsPull())
+	assert.Equal(t, 4, merged.PullNumber(), "the merged pull request is preferred over a declined one")
+	assert.Equal(t, "Features", merged.Group())
+	assert.Equal(t, "https://bitbucket.example.com/projects/PROJ/repos/project/pull-requests/4", merged.PullURL())
+	assert.Equal(t, "octocat", merged.Author())
+	assert.Equal(t, "https://bitbucket.example.com/users/octocat", merged.AuthorURL())
+	assert.Equal(t, "2026-01-03T10:00:00Z", merged.Date().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "2026-01-06T10:00:00Z", merged.MergedAt().Format("2006-01-02T15:04:05Z07:00"), "closedDate of a merged pull request")
+	assert.Equal(t, "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", merged.MergeCommit())
+
+	declined := byHash["3333333333333333333333333333333333333333"]
+	assert.False(t, declined.IsPull(), "a declined pull request didn't merge the commit")
+	assert.Equal(t, "Jim Schubert", declined.Author())
+	assert.Empty(t, declined.PullURL())
+	assert.True(t, declined.MergedAt().IsZero())
+}
+
+func Test_bitbucketServerService_commitsInRange(t *testing.T) {
+	server := newBitbucketServer(t)
+	config := &model.Config{Owner: "PROJ", Repo: "project"}
+
+	store, err := NewBitbucketServerService(server.URL+"/rest/api/1.0/", "secret", server.Client())
+	assert.NoError(t, err)
+	s := store.WithConfig(config).(*bitbucketServerService)
+
+	tests := []struct {
+		name    string
+		maximum int
+		want    []string
+	}{
+		{"follows pages to the last page", 500, []string{
+			"5555555555555555555555555555555555555555",
+			"4444444444444444444444444444444444444444",
+			"3333333333333333333333333333333333333333",
+			"2222222222222222222222222222222222222222",
+			"1111111111111111111111111111111111111111",
+		}},
+		{"stops at maximum within the first page", 1, []string{"5555555555555555555555555555555555555555"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			commits, err := s.commitsInRange(&ctx, "v1.0.0", "v1.1.0", tt.maximum)
+			assert.NoError(t, err)
+
+			got := make([]string, 0, len(commits))
+			for _, commit := range commits {
+				got = append(got, commit.ID)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_bitbucketServerService_Changes_error(t *testing.T) {
+	server := newBitbucketServer(t)
+	config := &model.Config{Owner: "PROJ", Repo: "missing"}
+
+	store, err := NewBitbucketServerService(server.URL, "secret", server.Client())
+	assert.NoError(t, err)
+
+	_, err = collect(t, store.WithConfig(config), "v1.0.0", "v1.1.0")
+	var responseError *ResponseError
+	if assert.ErrorAs(t, err, &responseError) {
+		assert.Equal(t, http.StatusNotFound, responseError.StatusCode)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// cacheExtension identifies files written by CacheTransport, so ClearCache never removes anything else
+const cacheExtension = ".changelog-cache.json"
+
+// cacheEntry is a cached API response as persisted to disk
+type cacheEntry struct {
+	URL      string      `json:"url"`
+	StoredAt time.Time   `json:"stored_at"`
+	Header   http.Header `json:"header"`
+	Body     []byte      `json:"body"`
+}
+
+// CacheTransport is an http.RoundTripper which persists successful GET responses to disk. Responses younger than TTL are
+// served without a request; older responses are revalidated with If-None-Match (or If-Modified-Since), so unchanged
+// resources are answered with 304 Not Modified, which doesn't count against GitHub's rate limit.
+type CacheTransport struct {
+	// Base performs requests; defaults to http.DefaultTransport
+	Base http.RoundTripper
+
+	// Dir is the directory containing cached responses
+	Dir string
+
+	// TTL is the duration for which cached responses are served without revalidation
+	TTL time.Duration
+
+	// Now is the time source for cache expiry; defaults to time.Now
+	Now func() time.Time
+}
+
+// NewCacheTransport creates a CacheTransport persisting responses to dir
+func NewCacheTransport(base http.RoundTripper, dir string, ttl time.Duration) *CacheTransport {
+	return &CacheTransport{Base: base, Dir: dir, TTL: ttl}
+}
+
+// RoundTrip serves req from the cache when fresh, otherwise performs req (conditionally, when a cached response exists)
+func (t *CacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	if req.Method != http.MethodGet || req.Header.Get("Range") != "" {
+		return base.RoundTrip(req)
+	}
+
+	path := filepath.Join(t.Dir, cacheKey(req)+cacheExtension)
+	entry := t.read(path)
+	if entry != nil && t.clock().Sub(entry.StoredAt) < t.TTL {
+		log.WithFields(log.Fields{"url": req.URL.String()}).Debug("cache hit")
+		return entry.response(req), nil
+	}
+
+	conditional := req
+	if entry != nil {
+		conditional = req.Clone(req.Context())
+		if etag := entry.Header.Get("ETag"); etag != "" {
+			conditional.Header.Set("If-None-Match", etag)
+		}
+		if lastModified := entry.Header.Get("Last-Modified"); lastModified != "" {
+			conditional.Header.Set("If-Modified-Since", lastModified)
+		}
+	}
+
+	resp, err := base.RoundTrip(conditional)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		log.WithFields(log.Fields{"url": req.URL.String()}).Debug("cache revalidated")
+		_ = resp.Body.Close()
+		for k, v := range resp.Header {
+			entry.Header[k] = v
+		}
+		entry.StoredAt = t.clock()
+		t.write(path, entry)
+		return entry.response(req), nil
+	}
+
+	if resp.StatusCode != http.StatusOK || (resp.Header.Get("ETag") == "" && resp.Header.Get("Last-Modified") == "") {
+		return resp, nil
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	t.write(path, &cacheEntry{URL: req.URL.String(), StoredAt: t.clock(), Header: resp.Header.Clone(), Body: body})
+	return resp, nil
+}
+
+func (t *CacheTransport) read(path string) *cacheEntry {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	entry := new(cacheEntry)
+	if err := json.Unmarshal(b, entry); err != nil {
+		log.WithFields(log.Fields{"error": err, "path": path}).Debug("ignoring unreadable cache entry")
+		return nil
+	}
+	if entry.Header == nil {
+		entry.Header = http.Header{}
+	}
+	return entry
+}
+
+// write persists entry via a temporary file, so concurrent readers never observe a partial entry
+func (t *CacheTransport) write(path string, entry *cacheEntry) {
+	b, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(t.Dir, 0o700)
+	}
+	var tmp *os.File
+	if err == nil {
+		tmp, err = os.CreateTemp(t.Dir, "tmp-*")
+	}
+	if err == nil {
+		_, err = tmp.Write(b)
+		closeErr := tmp.Close()
+		if err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(tmp.Name(), path)
+		}
+		if err != nil {
+			_ = os.Remove(tmp.Name())
+		}
+	}
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "path": path}).Warn("Unable to write cache entry")
+	}
+}
+
+func (t *CacheTransport) clock() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+// response creates a response from the cached entry. Rate limit headers are stale, so they're omitted.
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	header := e.Header.Clone()
+	for k := range header {
+		if strings.HasPrefix(strings.ToLower(k), "x-ratelimit-") {
+			header.Del(k)
+		}
+	}
+
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
+
+// cacheKey identifies a request by its URL and the headers which affect the response, including credentials,
+// so responses are never shared between tokens with different access
+func cacheKey(req *http.Request) string {
+	h := sha256.New()
+	for _, part := range []string{req.URL.String(), req.Header.Get("Accept"), req.Header.Get("Authorization"), req.Header.Get("PRIVATE-TOKEN")} {
+		_, _ = io.WriteString(h, part)
+		_, _ = h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ClearCache removes all cached responses from dir
+func ClearCache(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), cacheExtension) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
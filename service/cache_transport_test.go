@@ -0,0 +1,186 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// cacheStep is a request made through the CacheTransport along with its expectations
+type cacheStep struct {
+	method        string
+	advance       time.Duration
+	authorization string
+	wantBody      string
+	wantRequest   bool
+	wantCondition string
+}
+
+func TestCacheTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		noValidators bool
+		changes      bool
+		steps        []cacheStep
+	}{
+		{
+			name: "fresh responses are served from disk",
+			steps: []cacheStep{
+				{wantBody: "body 1", wantRequest: true},
+				{advance: 30 * time.Minute, wantBody: "body 1"},
+			},
+		},
+		{
+			name: "stale responses are revalidated via ETag",
+			steps: []cacheStep{
+				{wantBody: "body 1", wantRequest: true},
+				{advance: 2 * time.Hour, wantBody: "body 1", wantRequest: true, wantCondition: `"v1"`},
+				{advance: 30 * time.Minute, wantBody: "body 1"},
+			},
+		},
+		{
+			name:    "changed responses replace the cached response",
+			changes: true,
+			steps: []cacheStep{
+				{wantBody: "body 1", wantRequest: true},
+				{advance: 2 * time.Hour, wantBody: "body 2", wantRequest: true, wantCondition: `"v1"`},
+				{advance: 2 * time.Hour, wantBody: "body 2", wantRequest: true, wantCondition: `"v2"`},
+			},
+		},
+		{
+			name:         "responses without validators aren't cached",
+			noValidators: true,
+			steps: []cacheStep{
+				{wantBody: "body 1", wantRequest: true},
+				{wantBody: "body 2", wantRequest: true},
+			},
+		},
+		{
+			name: "other methods bypass the cache",
+			steps: []cacheStep{
+				{method: http.MethodPost, wantBody: "body 1", wantRequest: true},
+				{method: http.MethodPost, wantBody: "body 2", wantRequest: true},
+			},
+		},
+		{
+			name: "responses aren't shared between credentials",
+			steps: []cacheStep{
+				{authorization: "Bearer one", wantBody: "body 1", wantRequest: true},
+				{authorization: "Bearer two", wantBody: "body 2", wantRequest: true},
+				{authorization: "Bearer one", wantBody: "body 1"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			served := 0
+			requested := false
+			condition := ""
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requested = true
+				condition = r.Header.Get("If-None-Match")
+				etag := `"v1"`
+				if tt.changes && served > 0 {
+					etag = `"v2"`
+				}
+				w.Header().Set("X-RateLimit-Remaining", "4999")
+				if !tt.noValidators {
+					w.Header().Set("ETag", etag)
+				}
+				if condition == etag {
+					w.WriteHeader(http.StatusNotModified)
+					return
+				}
+				served++
+				_, _ = fmt.Fprintf(w, "body %d", served)
+			}))
+			t.Cleanup(server.Close)
+
+			now := time.Unix(1767348000, 0)
+			transport := NewCacheTransport(server.Client().Transport, t.TempDir(), time.Hour)
+			transport.Now = func() time.Time { return now }
+
+			for i, step := range tt.steps {
+				now = now.Add(step.advance)
+				requested = false
+				condition = ""
+				method := step.method
+				if method == "" {
+					method = http.MethodGet
+				}
+
+				req, _ := http.NewRequestWithContext(t.Context(), method, server.URL+"/repos/o/r/pulls/1", nil)
+				if step.authorization != "" {
+					req.Header.Set("Authorization", step.authorization)
+				}
+				resp, err := transport.RoundTrip(req)
+				if !assert.NoError(t, err, "step %d", i) {
+					return
+				}
+				body, _ := io.ReadAll(resp.Body)
+				_ = resp.Body.Close()
+
+				assert.Equal(t, http.StatusOK, resp.StatusCode, "step %d", i)
+				assert.Equal(t, step.wantBody, string(body), "step %d", i)
+				assert.Equal(t, step.wantRequest, requested, "step %d", i)
+				assert.Equal(t, step.wantCondition, condition, "step %d", i)
+				if !step.wantRequest {
+					assert.Empty(t, resp.Header.Get("X-RateLimit-Remaining"), "step %d: cached responses omit stale rate limits", i)
+				}
+			}
+		})
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	dir := t.TempDir()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = io.WriteString(w, "body")
+	}))
+	t.Cleanup(server.Close)
+
+	transport := NewCacheTransport(server.Client().Transport, dir, time.Hour)
+	for _, path := range []string{"/one", "/two"} {
+		req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+path, nil)
+		resp, err := transport.RoundTrip(req)
+		if assert.NoError(t, err) {
+			_ = resp.Body.Close()
+		}
+	}
+	unrelated := filepath.Join(dir, "notes.txt")
+	assert.NoError(t, os.WriteFile(unrelated, []byte("keep"), 0o600))
+
+	assert.NoError(t, ClearCache(dir))
+
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.Equal(t, []string{"notes.txt"}, names, "only cached responses are removed")
+
+	assert.NoError(t, ClearCache(filepath.Join(dir, "missing")), "a missing cache directory is already clear")
+}
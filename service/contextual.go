@@ -20,18 +20,44 @@ import (
 	"time"
 
 	"github.com/google/go-github/v29/github"
+
+	"github.com/jimschubert/changelog/model"
 )
 
+// defaultRequestTimeout bounds each request when the config doesn't define request_timeout
+const defaultRequestTimeout = 10 * time.Second
+
 type clientContext struct{}
+type attemptTimeoutContext struct{}
 type Contextual struct {
-	client *github.Client
+	client  *github.Client
+	timeout time.Duration
 }
 
 func newContextual(client *github.Client) *Contextual {
-	return &Contextual{client: client}
+	return &Contextual{client: client, timeout: defaultRequestTimeout}
+}
+
+// WithAttemptTimeouts marks ctx as issuing requests through a RetryTransport whose Timeout bounds each attempt.
+// Stores then leave requests made with ctx unbounded, as their deadline would also cut short waits for rate limits.
+func WithAttemptTimeouts(ctx context.Context) context.Context {
+	return context.WithValue(ctx, attemptTimeoutContext{}, true)
 }
 
-// CreateContext creates a known context from a parent context (c)
+// withConfig applies the configured request timeout, returning ctx (which may be nil) for chaining.
+// An invalid request_timeout retains the current timeout; Changelog reports it before any store is used.
+func (ctx *Contextual) withConfig(config *model.Config) *Contextual {
+	if ctx == nil || config == nil {
+		return ctx
+	}
+	if timeout, err := config.GetRequestTimeout(); err == nil {
+		ctx.timeout = timeout
+	}
+	return ctx
+}
+
+// CreateContext creates a known context from a parent context (c), bounded by the request timeout unless c is marked by
+// WithAttemptTimeouts
 func (ctx *Contextual) CreateContext(c *context.Context) (context.Context, context.CancelFunc) {
 	var parentContext context.Context
 	if c != nil {
@@ -45,12 +71,21 @@ func (ctx *Contextual) CreateContext(c *context.Context) (context.Context, conte
 		parentContext = context.WithValue(parentContext, clientContext{}, client)
 	}
 
-	timeoutCtx, cancel := context.WithTimeout(parentContext, 10*time.Second)
-	return timeoutCtx, cancel
+	if bounded, _ := parentContext.Value(attemptTimeoutContext{}).(bool); bounded {
+		return context.WithCancel(parentContext)
+	}
+	timeout := defaultRequestTimeout
+	if ctx != nil && ctx.timeout > 0 {
+		timeout = ctx.timeout
+	}
+	return context.WithTimeout(parentContext, timeout)
 }
 
-// GetClient returns the client, if it exists
+// GetClient returns the client, if it exists (a nil Contextual has none)
 func (ctx *Contextual) GetClient() *github.Client {
+	if ctx == nil {
+		return nil
+	}
 	return ctx.client
 }
 
@@ -0,0 +1,55 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+func TestContextual_CreateContext(t *testing.T) {
+	requestTimeout := "2s"
+	tests := []struct {
+		name         string
+		config       *model.Config
+		parent       context.Context
+		wantDeadline time.Duration
+	}{
+		{"defaults to the request timeout", nil, context.Background(), defaultRequestTimeout},
+		{"applies the configured request timeout", &model.Config{RequestTimeout: &requestTimeout}, context.Background(), 2 * time.Second},
+		{"leaves requests through a RetryTransport to its Timeout", &model.Config{RequestTimeout: &requestTimeout}, WithAttemptTimeouts(context.Background()), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := tt.parent
+			ctx, cancel := newContextual(nil).withConfig(tt.config).CreateContext(&parent)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tt.wantDeadline == 0 {
+				assert.False(t, ok, "unexpected deadline %s", deadline)
+				return
+			}
+			if assert.True(t, ok) {
+				assert.WithinDuration(t, time.Now().Add(tt.wantDeadline), deadline, time.Second)
+			}
+		})
+	}
+}
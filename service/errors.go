@@ -0,0 +1,74 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-github/v29/github"
+)
+
+var (
+	// ErrRefNotFound is matched (via errors.Is) by a RefNotFoundError
+	ErrRefNotFound = errors.New("ref not found")
+
+	// ErrRateLimited is matched (via errors.Is) by a RateLimitError
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// RefNotFoundError is returned when a store can't resolve the revision(s) bounding a changelog. Remote stores
+// report the compared range as Ref, since their APIs don't identify which end of it is missing.
+type RefNotFoundError struct {
+	Ref string
+	Err error
+}
+
+func (e *RefNotFoundError) Error() string {
+	return fmt.Sprintf("unable to resolve %q: %v", e.Ref, e.Err)
+}
+
+func (e *RefNotFoundError) Unwrap() error {
+	return e.Err
+}
+
+// Is allows errors.Is(err, ErrRefNotFound)
+func (e *RefNotFoundError) Is(target error) bool {
+	return target == ErrRefNotFound
+}
+
+// compareError converts a 404 from an API comparing from...to into a RefNotFoundError; other errors are returned as-is.
+// A repository which doesn't exist (or isn't visible to the token) is also a 404, and is reported the same way.
+func compareError(err error, from string, to string) error {
+	if isNotFound(err) {
+		return &RefNotFoundError{Ref: from + "..." + to, Err: err}
+	}
+	return err
+}
+
+// isNotFound determines whether err is a 404 response from a REST API or the GitHub API
+func isNotFound(err error) bool {
+	var responseError *ResponseError
+	var githubError *github.ErrorResponse
+	switch {
+	case errors.As(err, &responseError):
+		return responseError.StatusCode == http.StatusNotFound
+	case errors.As(err, &githubError):
+		return githubError.Response != nil && githubError.Response.StatusCode == http.StatusNotFound
+	default:
+		return false
+	}
+}
@@ -0,0 +1,62 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_compareError(t *testing.T) {
+	githubResponse := func(status int) *github.ErrorResponse {
+		return &github.ErrorResponse{Response: &http.Response{StatusCode: status, Request: &http.Request{Method: http.MethodGet, URL: &url.URL{}}}}
+	}
+	tests := []struct {
+		name           string
+		err            error
+		wantRefMissing bool
+	}{
+		{"rest api not found", &ResponseError{StatusCode: http.StatusNotFound}, true},
+		{"github api not found", githubResponse(http.StatusNotFound), true},
+		{"wrapped not found", fmt.Errorf("compare failed: %w", &ResponseError{StatusCode: http.StatusNotFound}), true},
+		{"rest api unauthorized", &ResponseError{StatusCode: http.StatusUnauthorized}, false},
+		{"github api server error", githubResponse(http.StatusInternalServerError), false},
+		{"network failure", errors.New("connection refused"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := compareError(tt.err, "v1", "v2")
+			assert.ErrorIs(t, err, tt.err, "the original error is retained")
+			assert.Equal(t, tt.wantRefMissing, errors.Is(err, ErrRefNotFound))
+
+			var refError *RefNotFoundError
+			if tt.wantRefMissing && assert.ErrorAs(t, err, &refError) {
+				assert.Equal(t, "v1...v2", refError.Ref)
+			}
+		})
+	}
+}
+
+func TestRateLimitError_Is(t *testing.T) {
+	err := fmt.Errorf("commit abc: %w", &url.Error{Op: "Get", URL: "https://api.github.com", Err: &RateLimitError{URL: "https://api.github.com"}})
+	assert.ErrorIs(t, err, ErrRateLimited, "rate limits are matched through the client's wrapping")
+	assert.NotErrorIs(t, &ResponseError{StatusCode: http.StatusForbidden}, ErrRateLimited)
+}
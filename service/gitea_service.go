@@ -0,0 +1,274 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+type giteaService struct {
+	contextual *Contextual
+	config     *model.Config
+	api        *restClient
+}
+
+type giteaUser struct {
+	Login   string `json:"login"`
+	HTMLURL string `json:"html_url"`
+}
+
+type giteaCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message   string `json:"message"`
+		Committer struct {
+			Name string    `json:"name"`
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+		Author struct {
+			Name string `json:"name"`
+		} `json:"author"`
+	} `json:"commit"`
+	Author  *giteaUser `json:"author"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
+}
+
+type giteaComparison struct {
+	TotalCommits int           `json:"total_commits"`
+	Commits      []giteaCommit `json:"commits"`
+}
+
+type giteaPullRequest struct {
+	Number  int       `json:"number"`
+	Title   string    `json:"title"`
+	HTMLURL string    `json:"html_url"`
+	User    giteaUser `json:"user"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	MergedAt       *time.Time `json:"merged_at"`
+	MergeCommitSHA *string    `json:"merge_commit_sha"`
+}
+
+// NewGiteaService creates a new Store for accessing commits from the Gitea or Forgejo API hosted at baseURL
+// (e.g. https://codeberg.org). An empty token allows read access to public repositories only.
+func NewGiteaService(baseURL string, token string, httpClient *http.Client) (Store, error) {
+	apiURL := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(apiURL, "/api/v1") {
+		apiURL += "/api/v1"
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "token "+token)
+	}
+
+	api, err := newRestClient(apiURL, httpClient, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &giteaService{contextual: newContextual(nil), api: api}, nil
+}
+
+// WithClient is a no-op, as Gitea is queried via its REST API rather than a GitHub client
+func (s *giteaService) WithClient(client *github.Client) Store {
+	return s
+}
+
+// WithConfig applies a Config instance to the Store
+func (s *giteaService) WithConfig(config *model.Config) Store {
+	s.config = config
+	s.contextual = s.contextual.withConfig(config)
+	return s
+}
+
+// GetContextual returns the context wrapper for this service
+func (s *giteaService) GetContextual() *Contextual {
+	return s.contextual
+}
+
+// Changes queries the service for commits, yielding each as a ChangeItem
+func (s *giteaService) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	list := func(ctx *context.Context) ([]giteaCommit, error) {
+		return s.compareCommits(ctx, from, to)
+	}
+	urls := urlBuilder(s.config)
+	convert := func(commit giteaCommit, ctx *context.Context) (*model.ChangeItem, error) {
+		return s.convertToChangeItem(commit, urls, ctx)
+	}
+	return streamCommits(ctx, s.contextual, s.config.GetConcurrency(), list, convert)
+}
+
+// compareCommits queries the commits between from and to, up to the configured maximum
+func (s *giteaService) compareCommits(parentContext *context.Context, from string, to string) ([]giteaCommit, error) {
+	compareContext, cancel := s.contextual.CreateContext(parentContext)
+	defer cancel()
+
+	comparison := new(giteaComparison)
+	if _, err := s.api.get(compareContext, s.repoPath("compare/"+escapeRef(from)+"..."+escapeRef(to)), nil, comparison); err != nil {
+		return nil, compareError(err, from, to)
+	}
+
+	commits := comparison.Commits
+	if maximum := s.config.GetMaxCommits(); len(commits) > maximum {
+		warnTruncated(from, to, len(commits), maximum)
+		commits = keepNewest(commits, maximum, func(commit giteaCommit) time.Time {
+			return commit.Commit.Committer.Date
+		})
+	}
+
+	return commits, nil
+}
+
+// DefaultBranch queries the repository's default branch
+func (s *giteaService) DefaultBranch(ctx context.Context) (string, error) {
+	repoContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+
+	repo := new(struct {
+		DefaultBranch string `json:"default_branch"`
+	})
+	if _, err := s.api.get(repoContext, strings.TrimSuffix(s.repoPath(""), "/"), nil, repo); err != nil {
+		return "", err
+	}
+	return repo.DefaultBranch, nil
+}
+
+// repoPath creates a path relative to the API for the configured repository
+func (s *giteaService) repoPath(resource string) string {
+	return "repos/" + url.PathEscape(s.config.Owner) + "/" + url.PathEscape(s.config.Repo) + "/" + resource
+}
+
+// escapeRef escapes each segment of ref for use in a path, keeping the slashes of names such as release/1.x which
+// Gitea's routes expect unescaped
+func escapeRef(ref string) string {
+	segments := strings.Split(ref, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func (s *giteaService) convertToChangeItem(commit giteaCommit, urls *model.URLBuilder, ctx *context.Context) (*model.ChangeItem, error) {
+	if len(commit.Parents) > 1 {
+		return nil, nil // Skip merge commits
+	}
+
+	message := commit.Commit.Message
+	title, _, _ := strings.Cut(message, "\n")
+	if s.config.ShouldExcludeByText(&title) {
+		return nil, nil
+	}
+
+	grouping := s.config.FindGroup(message)
+	if s.config.ShouldExcludeByText(grouping) {
+		return nil, nil
+	}
+
+	ci := &model.ChangeItem{
+		AuthorRaw:        &commit.Commit.Author.Name,
+		CommitMessageRaw: &message,
+		DateRaw:          &commit.Commit.Committer.Date,
+		CommitHashRaw:    &commit.SHA,
+		CommitURLRaw:     &commit.HTMLURL,
+		GroupRaw:         grouping,
+	}
+
+	if urls != nil {
+		commitURL := urls.CommitURL(ci.CommitHash())
+		ci.CommitURLRaw = &commitURL
+	}
+	if commit.Author != nil && commit.Author.Login != "" {
+		ci.AuthorRaw = &commit.Author.Login
+		ci.AuthorURLRaw = &commit.Author.HTMLURL
+	}
+
+	pullRequest, exclude, err := s.shouldExcludeViaPullRequest(commit.SHA, ctx)
+	if err != nil {
+		return nil, &CommitError{SHA: commit.SHA, Err: fmt.Errorf("unable to query pull request: %w", err)}
+	}
+	if exclude {
+		return nil, nil
+	}
+
+	if pullRequest != nil {
+		isPull := true
+		ci.IsPullRaw = &isPull
+		ci.PullSourceRaw = model.PullFromAssociation.Ptr()
+		ci.PullURLRaw = &pullRequest.HTMLURL
+		if urls != nil {
+			pullURL := urls.PullURL(strconv.Itoa(pullRequest.Number))
+			ci.PullURLRaw = &pullURL
+		}
+		ci.AuthorRaw = &pullRequest.User.Login
+		ci.AuthorURLRaw = &pullRequest.User.HTMLURL
+
+		labels := make([]string, 0, len(pullRequest.Labels))
+		for _, label := range pullRequest.Labels {
+			labels = append(labels, label.Name)
+		}
+		ci.PullNumberRaw = &pullRequest.Number
+		ci.PullTitleRaw = &pullRequest.Title
+		ci.PullAuthorRaw = &pullRequest.User.Login
+		ci.PullAuthorURLRaw = &pullRequest.User.HTMLURL
+		ci.MergedAtRaw = pullRequest.MergedAt
+		ci.MergeCommitRaw = pullRequest.MergeCommitSHA
+		ci.LabelsRaw = labels
+	}
+
+	return ci, nil
+}
+
+// shouldExcludeViaPullRequest finds the pull request which merged sha, evaluating its title and labels against exclusion rules
+func (s *giteaService) shouldExcludeViaPullRequest(sha string, parent *context.Context) (*giteaPullRequest, bool, error) {
+	timeout, cancel := s.contextual.CreateContext(parent)
+	defer cancel()
+
+	pullRequest := new(giteaPullRequest)
+	if _, err := s.api.get(timeout, s.repoPath("commits/"+url.PathEscape(sha)+"/pull"), nil, pullRequest); err != nil {
+		var responseError *ResponseError
+		if errors.As(err, &responseError) && responseError.StatusCode == http.StatusNotFound {
+			// the commit wasn't merged via a pull request
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if s.config.ShouldExcludeByText(&pullRequest.Title) {
+		return pullRequest, true, nil
+	}
+	for i := range pullRequest.Labels {
+		if s.config.ShouldExcludeByText(&pullRequest.Labels[i].Name) {
+			return pullRequest, true, nil
+		}
+	}
+	return pullRequest, false, nil
+}
@@ -0,0 +1,116 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+func Test_giteaService_Changes(t *testing.T) {
+	server := newAPIServer(t, "Authorization", "token secret", map[string]http.HandlerFunc{
+		"/api/v1/repos/owner/project/compare/v1.0.0...v1.1.0": respond(`{"total_commits": 5, "commits": [
+			{"sha": "1111111111111111111111111111111111111111", "html_url": "https://git.example.com/owner/project/commit/1111111111111111111111111111111111111111",
+			 "commit": {"message": "Tidy the readme", "author": {"name": "Jim Schubert"}, "committer": {"name": "Jim Schubert", "date": "2026-01-02T10:00:00Z"}},
+			 "author": null, "parents": [{"sha": "0000000000000000000000000000000000000000"}]},
+			{"sha": "2222222222222222222222222222222222222222", "html_url": "https://git.example.com/owner/project/commit/2222222222222222222222222222222222222222",
+			 "commit": {"message": "Support forgejo", "author": {"name": "Jim Schubert"}, "committer": {"name": "Jim Schubert", "date": "2026-01-03T10:00:00Z"}},
+			 "author": {"login": "jimschubert", "html_url": "https://git.example.com/jimschubert"}, "parents": [{"sha": "1111111111111111111111111111111111111111"}]},
+			{"sha": "3333333333333333333333333333333333333333", "html_url": "https://git.example.com/owner/project/commit/3333333333333333333333333333333333333333",
+			 "commit": {"message": "Flaky lookup", "author": {"name": "Jim Schubert"}, "committer": {"name": "Jim Schubert", "date": "2026-01-04T10:00:00Z"}},
+			 "parents": [{"sha": "2222222222222222222222222222222222222222"}]},
+			{"sha": "4444444444444444444444444444444444444444", "html_url": "https://git.example.com/owner/project/commit/4444444444444444444444444444444444444444",
+			 "commit": {"message": "Experimental gizmo", "author": {"name": "Jim Schubert"}, "committer": {"name": "Jim Schubert", "date": "2026-01-05T10:00:00Z"}},
+			 "parents": [{"sha": "3333333333333333333333333333333333333333"}]},
+			{"sha": "5555555555555555555555555555555555555555", "html_url": "https://git.example.com/owner/project/commit/5555555555555555555555555555555555555555",
+			 "commit": {"message": "Internal tooling", "author": {"name": "Jim Schubert"}, "committer": {"name": "Jim Schubert", "date": "2026-01-06T10:00:00Z"}},
+			 "parents": [{"sha": "4444444444444444444444444444444444444444"}]}
+		]}`),
+		"/api/v1/repos/owner/project/commits/1111111111111111111111111111111111111111/pull": http.NotFound,
+		"/api/v1/repos/owner/project/commits/2222222222222222222222222222222222222222/pull": respond(`{"number": 4, "title": "Support Forgejo", "html_url": "https://git.example.com/owner/project/pulls/4",
+			"user": {"login": "octocat", "html_url": "https://git.example.com/octocat"}, "labels": [{"name": "enhancement"}],
+			"merged_at": "2026-01-06T10:00:00Z", "merge_commit_sha": "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee"}`),
+		"/api/v1/repos/owner/project/commits/3333333333333333333333333333333333333333/pull": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		},
+		"/api/v1/repos/owner/project/commits/4444444444444444444444444444444444444444/pull": respond(`{"number": 5, "title": "Experimental gizmo", "html_url": "https://git.example.com/owner/project/pulls/5",
+			"user": {"login": "octocat"}, "labels": [{"name": "do not release"}], "merged_at": "2026-01-07T10:00:00Z"}`),
+		"/api/v1/repos/owner/project/commits/5555555555555555555555555555555555555555/pull": respond(`{"number": 6, "title": "Internal tooling (do not release)", "html_url": "https://git.example.com/owner/project/pulls/6",
+			"user": {"login": "octocat"}, "labels": [], "merged_at": "2026-01-08T10:00:00Z"}`),
+	})
+	enterprise := "https://git.example.com"
+	config := &model.Config{
+		Owner:      "owner",
+		Repo:       "project",
+		Provider:   model.Gitea.Ptr(),
+		Enterprise: &enterprise,
+		Exclude:    []string{"do not release"},
+	}
+
+	store, err := NewGiteaService(server.URL, "secret", server.Client())
+	assert.NoError(t, err)
+
+	items, err := collect(t, store.WithConfig(config), "v1.0.0", "v1.1.0")
+	var commitError *CommitError
+	if assert.ErrorAs(t, err, &commitError, "failures other than 404 to look up a pull request are reported per commit") {
+		assert.Equal(t, "3333333333333333333333333333333333333333", commitError.SHA)
+	}
+	assert.Len(t, items, 2)
+
+	byHash := make(map[string]model.ChangeItem)
+	for _, item := range items {
+		byHash[item.CommitHash()] = item
+	}
+	assert.NotContains(t, byHash, "4444444444444444444444444444444444444444", "pull request label matches an exclusion")
+	assert.NotContains(t, byHash, "5555555555555555555555555555555555555555", "pull request title matches an exclusion")
+
+	direct := byHash["1111111111111111111111111111111111111111"]
+	assert.False(t, direct.IsPull(), "404 from the pull lookup means the commit wasn't merged via a pull request")
+	assert.Equal(t, "Jim Schubert", direct.Author(), "commits without a linked account fall back to the git author")
+	assert.Equal(t, "https://git.example.com/owner/project/commit/1111111111111111111111111111111111111111", direct.CommitURL())
+
+	merged := byHash["2222222222222222222222222222222222222222"]
+	assert.True(t, merged.IsPull())
+	assert.Equal(t, "https://git.example.com/owner/project/pulls/4", merged.PullURL())
+	assert.Equal(t, "octocat", merged.Author())
+	assert.Equal(t, "https://git.example.com/octocat", merged.AuthorURL())
+	assert.Equal(t, 4, merged.PullNumber())
+	assert.Equal(t, "Support Forgejo", merged.PullTitle())
+	assert.Equal(t, []string{"enhancement"}, merged.Labels())
+	assert.Equal(t, "2026-01-06T10:00:00Z", merged.MergedAt().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", merged.MergeCommit())
+}
+
+func Test_giteaService_compareCommits(t *testing.T) {
+	server := newAPIServer(t, "Authorization", "token secret", map[string]http.HandlerFunc{
+		"/api/v1/repos/owner/project/compare/release/1.x...feature/foo": respond(`{"total_commits": 1, "commits": [
+			{"sha": "1111111111111111111111111111111111111111", "commit": {"message": "Tidy the readme"}}
+		]}`),
+	})
+	config := &model.Config{Owner: "owner", Repo: "project"}
+
+	store, err := NewGiteaService(server.URL, "secret", server.Client())
+	assert.NoError(t, err)
+	s := store.WithConfig(config).(*giteaService)
+
+	ctx := t.Context()
+	commits, err := s.compareCommits(&ctx, "release/1.x", "feature/foo")
+	assert.NoError(t, err, "refs containing a slash are sent as path segments")
+	assert.Len(t, commits, 1)
+}
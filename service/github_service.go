@@ -16,16 +16,21 @@ package service
 
 import (
 	"context"
-	"strconv"
+	"fmt"
+	"iter"
+	"slices"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/google/go-github/v29/github"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/jimschubert/changelog/model"
 )
 
+// comparePageSize is the number of commits requested per page of the compare API (the API's maximum)
+const comparePageSize = 100
+
 type githubService struct {
 	contextual *Contextual
 	config     *model.Config
@@ -33,19 +38,20 @@ type githubService struct {
 
 // NewGitHubService creates a new Store for accessing commits from the GitHub API
 func NewGitHubService() Store {
-	service := &githubService{}
+	service := &githubService{contextual: newContextual(nil)}
 	return service
 }
 
 // WithClient applies a GitHub client to the Store
 func (s *githubService) WithClient(client *github.Client) Store {
-	s.contextual = newContextual(client)
+	s.contextual = newContextual(client).withConfig(s.config)
 	return s
 }
 
 // WithConfig applies a Config instance to the Store
 func (s *githubService) WithConfig(config *model.Config) Store {
 	s.config = config
+	s.contextual = s.contextual.withConfig(config)
 	return s
 }
 
@@ -54,45 +60,263 @@ func (s *githubService) GetContextual() *Contextual {
 	return s.contextual
 }
 
-// Process queries the service for commits, converting to a ChangeItem and sending to the channel
-func (s *githubService) Process(parentContext *context.Context, wg *sync.WaitGroup, ciChan chan *model.ChangeItem, from string, to string) error {
+// Changes queries the service for commits, yielding each as a ChangeItem
+func (s *githubService) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	list := func(ctx *context.Context) ([]github.RepositoryCommit, error) {
+		if from == "" {
+			return s.listCommits(ctx, to, s.config.GetMaxCommits())
+		}
+		return s.compareCommits(ctx, from, to, s.config.GetMaxCommits())
+	}
+	urls := urlBuilder(s.config)
+	convert := func(commit github.RepositoryCommit, ctx *context.Context) (*model.ChangeItem, error) {
+		return s.convertToChangeItem(commit, urls, ctx)
+	}
+	return streamCommits(ctx, s.contextual, s.config.GetConcurrency(), list, convert)
+}
+
+// compareCommits pages through the compare API, collecting commits between from and to up to maximum.
+// A single compare request is limited to 250 commits, so larger ranges must be requested page by page. Beyond maximum,
+// the newest commits are kept.
+// see https://docs.github.com/en/rest/commits/commits#compare-two-commits
+func (s *githubService) compareCommits(parentContext *context.Context, from string, to string, maximum int) ([]github.RepositoryCommit, error) {
 	contextual := s.contextual
+	client := contextual.GetClient()
 
-	compareContext, cancel := contextual.CreateContext(parentContext)
-	defer cancel()
+	commits := make([]github.RepositoryCommit, 0)
+	total, skipped := 0, 0
+	for page := 1; ; page++ {
+		u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=%d&page=%d", s.config.Owner, s.config.Repo, from, to, comparePageSize, page)
+		req, err := client.NewRequest("GET", u, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		comparison := new(github.CommitsComparison)
+		compareContext, cancel := contextual.CreateContext(parentContext)
+		_, err = client.Do(compareContext, req, comparison)
+		cancel()
+		if err != nil {
+			return nil, compareError(err, from, to)
+		}
+
+		total = comparison.GetTotalCommits()
+		if page == 1 && total > maximum {
+			// pages are ordered from the oldest commit, so those holding only omitted commits are skipped
+			if first := (total-maximum)/comparePageSize + 1; first > 1 {
+				page = first - 1
+				skipped = page * comparePageSize
+				continue
+			}
+		}
+		commits = append(commits, comparison.Commits...)
+
+		log.WithFields(log.Fields{
+			"page":  page,
+			"count": len(commits),
+			"total": total,
+		}).Debug("retrieved page of compare results")
+
+		if len(comparison.Commits) < comparePageSize || skipped+len(commits) >= total {
+			break
+		}
+	}
 
+	if len(commits) > maximum {
+		commits = commits[len(commits)-maximum:]
+	}
+	if total > maximum {
+		warnTruncated(from, to, total, maximum)
+	}
+
+	return commits, nil
+}
+
+// listCommits pages through the commits reachable from to, up to maximum, for a range starting at the repository root.
+// The API lists the newest commit first, so the result is reversed to match the order of compareCommits.
+// see https://docs.github.com/en/rest/commits/commits#list-commits
+func (s *githubService) listCommits(parentContext *context.Context, to string, maximum int) ([]github.RepositoryCommit, error) {
+	contextual := s.contextual
 	client := contextual.GetClient()
 
-	comparison, _, compareError := client.Repositories.CompareCommits(compareContext, s.config.Owner, s.config.Repo, from, to)
-	if compareError != nil {
-		return compareError
+	commits := make([]github.RepositoryCommit, 0)
+	opts := &github.CommitsListOptions{SHA: to, ListOptions: github.ListOptions{PerPage: comparePageSize}}
+	truncated := false
+	for {
+		listContext, cancel := contextual.CreateContext(parentContext)
+		page, resp, err := client.Repositories.ListCommits(listContext, s.config.Owner, s.config.Repo, opts)
+		cancel()
+		if err != nil {
+			return nil, compareError(err, "", to)
+		}
+
+		for _, commit := range page {
+			commits = append(commits, *commit)
+		}
+
+		log.WithFields(log.Fields{
+			"page":  opts.Page,
+			"count": len(commits),
+		}).Debug("retrieved page of commits")
+
+		truncated = resp.NextPage != 0
+		if !truncated || len(commits) >= maximum {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if len(commits) > maximum {
+		truncated = true
+		commits = commits[:maximum]
+	}
+	if truncated {
+		// pages are ordered from the newest commit, so the oldest are omitted
+		warnTruncated("", to, 0, maximum)
+	}
+	slices.Reverse(commits)
+	return commits, nil
+}
+
+// DefaultBranch queries the repository's default branch
+// see https://docs.github.com/en/rest/repos/repos#get-a-repository
+func (s *githubService) DefaultBranch(ctx context.Context) (string, error) {
+	repoContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+	repo, _, err := s.contextual.GetClient().Repositories.Get(repoContext, s.config.Owner, s.config.Repo)
+	if err != nil {
+		return "", err
+	}
+	return repo.GetDefaultBranch(), nil
+}
+
+// Ref resolves revision to its commit via the git database API, using the tagger's date and message when revision names
+// an annotated tag
+// see https://docs.github.com/en/rest/git/refs#get-a-reference
+func (s *githubService) Ref(ctx context.Context, revision string) (*model.RefInfo, error) {
+	info := &model.RefInfo{Name: revision, Type: model.CommitRef}
+	ref, err := s.gitRef(ctx, "tags/"+revision)
+	if err != nil {
+		return nil, err
+	}
+	if ref != nil {
+		info.Type = model.TagRef
+		if ref.GetObject().GetType() == "tag" {
+			if err := s.annotate(ctx, info, ref.GetObject().GetSHA()); err != nil {
+				return nil, err
+			}
+			return info, nil
+		}
+	} else if ref, err = s.gitRef(ctx, "heads/"+revision); err != nil {
+		return nil, err
+	} else if ref != nil {
+		info.Type = model.BranchRef
+	}
+
+	// the commit API resolves branches, lightweight tags and SHAs alike
+	commitContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+	commit, _, err := s.contextual.GetClient().Repositories.GetCommit(commitContext, s.config.Owner, s.config.Repo, revision)
+	if err != nil {
+		if isNotFound(err) {
+			return nil, &RefNotFoundError{Ref: revision, Err: err}
+		}
+		return nil, err
 	}
+	info.SHA = commit.GetSHA()
+	info.Date = commit.GetCommit().GetCommitter().GetDate()
+	return info, nil
+}
 
-	maximum := min(len((*comparison).Commits), s.config.GetMaxCommits())
-	commits := make([]github.RepositoryCommit, maximum)
+// gitRef queries a fully qualified reference (e.g. tags/v1.0.0), returning nil when it doesn't exist
+func (s *githubService) gitRef(ctx context.Context, name string) (*github.Reference, error) {
+	client := s.contextual.GetClient()
+	req, err := client.NewRequest("GET", fmt.Sprintf("repos/%v/%v/git/ref/%v", s.config.Owner, s.config.Repo, name), nil)
+	if err != nil {
+		return nil, err
+	}
 
-	copy(commits, (*comparison).Commits)
-	for _, commit := range commits {
-		wg.Add(1)
-		go func(commit github.RepositoryCommit) {
-			newContext, newCancel := contextual.CreateContext(parentContext)
-			defer newCancel()
-			s.convertToChangeItem(&commit, ciChan, wg, &newContext)
-		}(commit)
+	refContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+	ref := new(github.Reference)
+	if _, err := client.Do(refContext, req, ref); err != nil {
+		if isNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
 	}
+	return ref, nil
+}
 
+// annotate describes the annotated tag object sha, including the SHA of the commit it tags
+func (s *githubService) annotate(ctx context.Context, info *model.RefInfo, sha string) error {
+	tagContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+	tag, _, err := s.contextual.GetClient().Git.GetTag(tagContext, s.config.Owner, s.config.Repo, sha)
+	if err != nil {
+		return err
+	}
+
+	info.SHA = tag.GetObject().GetSHA()
+	info.Date = tag.GetTagger().GetDate()
+	info.Tagger = tag.GetTagger().GetName()
+	info.TaggerEmail = tag.GetTagger().GetEmail()
+	info.Annotation = strings.TrimSpace(tag.GetMessage())
 	return nil
 }
 
-func (s *githubService) convertToChangeItem(commit *github.RepositoryCommit, ch chan *model.ChangeItem, wg *sync.WaitGroup, ctx *context.Context) {
-	defer wg.Done()
+// Tags pages through the repository's tags
+// see https://docs.github.com/en/rest/repos/repos#list-repository-tags
+func (s *githubService) Tags(ctx context.Context) ([]Tag, error) {
+	client := s.contextual.GetClient()
+	opts := &github.ListOptions{PerPage: comparePageSize}
+	tags := make([]Tag, 0)
+	for {
+		pageContext, cancel := s.contextual.CreateContext(&ctx)
+		page, resp, err := client.Repositories.ListTags(pageContext, s.config.Owner, s.config.Repo, opts)
+		cancel()
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range page {
+			tags = append(tags, Tag{Name: tag.GetName(), SHA: tag.GetCommit().GetSHA()})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	log.WithFields(log.Fields{"count": len(tags)}).Debug("retrieved tags")
+	return tags, nil
+}
+
+// Precedes determines whether revision is an ancestor of to, via the compare API's status of to relative to revision
+func (s *githubService) Precedes(ctx context.Context, revision string, to string) (bool, error) {
+	client := s.contextual.GetClient()
+	u := fmt.Sprintf("repos/%v/%v/compare/%v...%v?per_page=1", s.config.Owner, s.config.Repo, revision, to)
+	req, err := client.NewRequest("GET", u, nil)
+	if err != nil {
+		return false, err
+	}
+
+	comparison := new(github.CommitsComparison)
+	compareContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+	if _, err := client.Do(compareContext, req, comparison); err != nil {
+		return false, compareError(err, revision, to)
+	}
+	return comparison.GetStatus() == "ahead", nil
+}
+
+func (s *githubService) convertToChangeItem(commit github.RepositoryCommit, urls *model.URLBuilder, ctx *context.Context) (*model.ChangeItem, error) {
 	var isMergeCommit = false
 	if commit.GetCommit() != nil && len(commit.GetCommit().Parents) > 1 {
 		isMergeCommit = true
 	}
 
 	if !isMergeCommit {
-		if !s.shouldExcludeViaRepositoryCommit(commit) {
+		if !s.shouldExcludeViaRepositoryCommit(&commit) {
 			excludeByGroup := false
 			var t *time.Time
 			var authorRaw *string
@@ -112,39 +336,32 @@ func (s *githubService) convertToChangeItem(commit *github.RepositoryCommit, ch
 
 			if !excludeByGroup {
 				// TODO: Max count?
+				commitURL := commit.HTMLURL
+				if urls != nil {
+					u := urls.CommitURL(commit.GetSHA())
+					commitURL = &u
+				}
 				ci := &model.ChangeItem{
 					AuthorRaw:        authorRaw,
 					AuthorURLRaw:     authorUrlRaw,
 					CommitMessageRaw: commit.Commit.Message,
 					DateRaw:          t,
 					CommitHashRaw:    commit.SHA,
-					CommitURLRaw:     commit.HTMLURL,
+					CommitURLRaw:     commitURL,
 					GroupRaw:         grouping,
 				}
 
-				applyPullPropertiesChangeItem(ci)
-
-				if ci.IsPull() {
-					pullId, e := ci.PullID()
-					if e != nil {
-						// In the unlikely case that an unexpected pull url is provided by GitHub API, just emit the change item
-						ch <- ci
-					} else {
-						// ignoring error here is intentional. if the ID is not parseable (should never happen), just evaluate the rules.
-						// the API call to retrieve PR will then also fail and exclude will be false.
-						pr, _ := strconv.Atoi(pullId)
-						contextual := s.contextual
-						_, exclude := shouldExcludeViaPullAttributes(pr, contextual, ctx, s.config)
-						if !exclude {
-							ch <- ci
-						}
-					}
-				} else {
-					ch <- ci
+				_, exclude, err := resolvePullRequest(ci, urls, s.contextual, ctx, s.config)
+				if err != nil {
+					return nil, &CommitError{SHA: commit.GetSHA(), Err: fmt.Errorf("unable to query pull request: %w", err)}
+				}
+				if !exclude {
+					return ci, nil
 				}
 			}
 		}
 	}
+	return nil, nil
 }
 
 func (s *githubService) shouldExcludeViaRepositoryCommit(commit *github.RepositoryCommit) bool {
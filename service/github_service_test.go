@@ -17,12 +17,20 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"path/filepath"
-	"sync"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v29/github"
+	log "github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/jimschubert/changelog/model"
@@ -150,13 +158,8 @@ func Test_githubService_convertToChangeItem_authorInfo(t *testing.T) {
 				config:     tt.fields.config,
 			}
 
-			ciChan := make(chan *model.ChangeItem)
-			wg := sync.WaitGroup{}
-			go func() {
-				wg.Add(1)
-				s.convertToChangeItem(tt.args.commit, ciChan, &wg, &background)
-			}()
-			ci := <-ciChan
+			ci, err := s.convertToChangeItem(*tt.args.commit, urlBuilder(tt.fields.config), &background)
+			assert.NoError(t, err)
 			assert.NotNil(t, ci)
 			assert.Equal(t, tt.compare.Author(), ci.Author())
 			assert.Equal(t, tt.compare.AuthorURL(), ci.AuthorURL())
@@ -164,3 +167,206 @@ func Test_githubService_convertToChangeItem_authorInfo(t *testing.T) {
 		})
 	}
 }
+
+// newCompareServer serves a paginated compare API response containing total commits
+func newCompareServer(t *testing.T, total int) (*github.Client, *int) {
+	t.Helper()
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/compare/v1...v2", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		commits := make([]github.RepositoryCommit, 0)
+		for i := (page - 1) * perPage; i < page*perPage && i < total; i++ {
+			commits = append(commits, github.RepositoryCommit{
+				SHA:    github.String(fmt.Sprintf("%040d", i)),
+				Commit: &github.Commit{Message: github.String(fmt.Sprintf("commit %d", i))},
+			})
+		}
+		_ = json.NewEncoder(w).Encode(github.CommitsComparison{TotalCommits: github.Int(total), Commits: commits})
+	})
+	// listing commits from v2 begins with the newest, linking to the next page while any remain
+	mux.HandleFunc("/repos/o/r/commits", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		assert.Equal(t, "v2", r.URL.Query().Get("sha"))
+		perPage, _ := strconv.Atoi(r.URL.Query().Get("per_page"))
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		commits := make([]github.RepositoryCommit, 0)
+		for i := total - 1 - (page-1)*perPage; i > total-1-page*perPage && i >= 0; i-- {
+			commits = append(commits, github.RepositoryCommit{SHA: github.String(fmt.Sprintf("%040d", i))})
+		}
+		if page*perPage < total {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		_ = json.NewEncoder(w).Encode(commits)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return client, &requests
+}
+
+func Test_githubService_Ref(t *testing.T) {
+	tagged := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	committed := time.Date(2026, time.February, 27, 9, 30, 0, 0, time.UTC)
+	sha := func(c string) string {
+		return strings.Repeat(c, 40)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/git/ref/", func(w http.ResponseWriter, r *http.Request) {
+		switch strings.TrimPrefix(r.URL.Path, "/repos/o/r/git/ref/") {
+		case "tags/v1.1.0":
+			_, _ = fmt.Fprintf(w, `{"ref": "refs/tags/v1.1.0", "object": {"type": "tag", "sha": "%s"}}`, sha("t"))
+		case "tags/v1.0.0":
+			_, _ = fmt.Fprintf(w, `{"ref": "refs/tags/v1.0.0", "object": {"type": "commit", "sha": "%s"}}`, sha("a"))
+		case "heads/main":
+			_, _ = fmt.Fprintf(w, `{"ref": "refs/heads/main", "object": {"type": "commit", "sha": "%s"}}`, sha("b"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	})
+	mux.HandleFunc("/repos/o/r/git/tags/"+sha("t"), func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(github.Tag{
+			Message: github.String("Release 1.1.0\n"),
+			Tagger:  &github.CommitAuthor{Name: github.String("Jim Schubert"), Email: github.String("jim@example.com"), Date: &tagged},
+			Object:  &github.GitObject{Type: github.String("commit"), SHA: github.String(sha("b"))},
+		})
+	})
+	mux.HandleFunc("/repos/o/r/commits/", func(w http.ResponseWriter, r *http.Request) {
+		revision := strings.TrimPrefix(r.URL.Path, "/repos/o/r/commits/")
+		commits := map[string]string{"v1.0.0": sha("a"), "main": sha("b"), sha("c"): sha("c")}
+		if _, ok := commits[revision]; !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "No commit found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(github.RepositoryCommit{
+			SHA:    github.String(commits[revision]),
+			Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &committed}},
+		})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	store := NewGitHubService().WithClient(client).WithConfig(&model.Config{Owner: "o", Repo: "r"}).(RefSource)
+
+	tests := []struct {
+		name     string
+		revision string
+		want     model.RefInfo
+	}{
+		{"annotated tag", "v1.1.0", model.RefInfo{Type: model.TagRef, SHA: sha("b"), Date: tagged, Tagger: "Jim Schubert", TaggerEmail: "jim@example.com", Annotation: "Release 1.1.0"}},
+		{"lightweight tag", "v1.0.0", model.RefInfo{Type: model.TagRef, SHA: sha("a"), Date: committed}},
+		{"branch", "main", model.RefInfo{Type: model.BranchRef, SHA: sha("b"), Date: committed}},
+		{"commit", sha("c"), model.RefInfo{Type: model.CommitRef, SHA: sha("c"), Date: committed}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := store.Ref(t.Context(), tt.revision)
+			if !assert.NoError(t, err) {
+				return
+			}
+			tt.want.Name = tt.revision
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+
+	_, err := store.Ref(t.Context(), "v9.9.9")
+	assert.ErrorIs(t, err, ErrRefNotFound)
+}
+
+func Test_githubService_compareCommits(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		maximum      int
+		wantCount    int
+		wantRequests int
+		wantWarning  bool
+	}{
+		{"single page", 42, 500, 42, 1, false},
+		{"exact page boundary", 200, 500, 200, 2, false},
+		{"beyond compare api limit of 250", 420, 500, 420, 5, false},
+		{"capped by maximum", 420, 250, 250, 5, true},
+		{"capped within the first page", 420, 350, 350, 5, true},
+		{"empty comparison", 0, 500, 0, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+			client, requests := newCompareServer(t, tt.total)
+			s := githubService{
+				contextual: newContextual(client),
+				config:     &model.Config{Owner: "o", Repo: "r"},
+			}
+			ctx := context.Background()
+			commits, err := s.compareCommits(&ctx, "v1", "v2", tt.maximum)
+			assert.NoError(t, err)
+			assert.Len(t, commits, tt.wantCount)
+			assert.Equal(t, tt.wantRequests, *requests)
+			if tt.wantCount > 0 {
+				// the newest commits are kept, so the range ends with the newest
+				assert.Equal(t, fmt.Sprintf("%040d", tt.total-tt.wantCount), commits[0].GetSHA())
+				assert.Equal(t, fmt.Sprintf("%040d", tt.total-1), commits[len(commits)-1].GetSHA())
+			}
+
+			warned := false
+			for _, entry := range hook.AllEntries() {
+				warned = warned || (entry.Level == log.WarnLevel && entry.Data["total"] == tt.total)
+			}
+			assert.Equal(t, tt.wantWarning, warned, "truncation is reported")
+		})
+	}
+}
+
+func Test_githubService_listCommits(t *testing.T) {
+	tests := []struct {
+		name         string
+		total        int
+		maximum      int
+		wantCount    int
+		wantRequests int
+		wantWarning  bool
+	}{
+		{"single page", 42, 500, 42, 1, false},
+		{"exact page boundary", 200, 500, 200, 2, false},
+		{"capped by maximum", 420, 250, 250, 3, true},
+		{"empty history", 0, 500, 0, 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hook := logtest.NewGlobal()
+			defer hook.Reset()
+			client, requests := newCompareServer(t, tt.total)
+			s := githubService{
+				contextual: newContextual(client),
+				config:     &model.Config{Owner: "o", Repo: "r"},
+			}
+			ctx := context.Background()
+			commits, err := s.listCommits(&ctx, "v2", tt.maximum)
+			assert.NoError(t, err)
+			assert.Len(t, commits, tt.wantCount)
+			assert.Equal(t, tt.wantRequests, *requests)
+			if tt.wantCount > 0 {
+				// the newest commits are kept, ordered from the oldest as compareCommits orders them
+				assert.Equal(t, fmt.Sprintf("%040d", tt.total-tt.wantCount), commits[0].GetSHA())
+				assert.Equal(t, fmt.Sprintf("%040d", tt.total-1), commits[len(commits)-1].GetSHA())
+			}
+
+			warned := false
+			for _, entry := range hook.AllEntries() {
+				warned = warned || entry.Level == log.WarnLevel
+			}
+			assert.Equal(t, tt.wantWarning, warned, "truncation is reported")
+		})
+	}
+}
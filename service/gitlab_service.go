@@ -0,0 +1,262 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"fmt"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+type gitlabService struct {
+	contextual *Contextual
+	config     *model.Config
+	api        *restClient
+}
+
+type gitlabCommit struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Message       string    `json:"message"`
+	AuthorName    string    `json:"author_name"`
+	CommittedDate time.Time `json:"committed_date"`
+	WebURL        string    `json:"web_url"`
+	ParentIDs     []string  `json:"parent_ids"`
+}
+
+type gitlabComparison struct {
+	Commits []gitlabCommit `json:"commits"`
+}
+
+type gitlabUser struct {
+	Username string `json:"username"`
+	WebURL   string `json:"web_url"`
+}
+
+type gitlabMergeRequest struct {
+	IID             int        `json:"iid"`
+	Title           string     `json:"title"`
+	State           string     `json:"state"`
+	Labels          []string   `json:"labels"`
+	WebURL          string     `json:"web_url"`
+	Author          gitlabUser `json:"author"`
+	MergedAt        *time.Time `json:"merged_at"`
+	MergeCommitSHA  string     `json:"merge_commit_sha"`
+	SquashCommitSHA string     `json:"squash_commit_sha"`
+}
+
+// NewGitLabService creates a new Store for accessing commits from the GitLab API hosted at baseURL (e.g. https://gitlab.com).
+// The token may be a personal, project or group access token; an empty token allows read access to public projects only.
+func NewGitLabService(baseURL string, token string, httpClient *http.Client) (Store, error) {
+	apiURL := strings.TrimSuffix(baseURL, "/")
+	if !strings.HasSuffix(apiURL, "/api/v4") {
+		apiURL += "/api/v4"
+	}
+
+	header := http.Header{}
+	if token != "" {
+		header.Set("PRIVATE-TOKEN", token)
+	}
+
+	api, err := newRestClient(apiURL, httpClient, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitlabService{contextual: newContextual(nil), api: api}, nil
+}
+
+// WithClient is a no-op, as GitLab is queried via its REST API rather than a GitHub client
+func (s *gitlabService) WithClient(client *github.Client) Store {
+	return s
+}
+
+// WithConfig applies a Config instance to the Store
+func (s *gitlabService) WithConfig(config *model.Config) Store {
+	s.config = config
+	s.contextual = s.contextual.withConfig(config)
+	return s
+}
+
+// GetContextual returns the context wrapper for this service
+func (s *gitlabService) GetContextual() *Contextual {
+	return s.contextual
+}
+
+// Changes queries the service for commits, yielding each as a ChangeItem
+func (s *gitlabService) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	list := func(ctx *context.Context) ([]gitlabCommit, error) {
+		return s.compareCommits(ctx, from, to)
+	}
+	urls := urlBuilder(s.config)
+	convert := func(commit gitlabCommit, ctx *context.Context) (*model.ChangeItem, error) {
+		return s.convertToChangeItem(commit, urls, ctx)
+	}
+	return streamCommits(ctx, s.contextual, s.config.GetConcurrency(), list, convert)
+}
+
+// compareCommits queries the commits between from and to, up to the configured maximum
+func (s *gitlabService) compareCommits(parentContext *context.Context, from string, to string) ([]gitlabCommit, error) {
+	compareContext, cancel := s.contextual.CreateContext(parentContext)
+	defer cancel()
+
+	// see https://docs.gitlab.com/api/repositories/#compare-branches-tags-or-commits
+	comparison := new(gitlabComparison)
+	query := url.Values{"from": {from}, "to": {to}}
+	if _, err := s.api.get(compareContext, s.projectPath("repository/compare"), query, comparison); err != nil {
+		return nil, compareError(err, from, to)
+	}
+
+	commits := comparison.Commits
+	if maximum := s.config.GetMaxCommits(); len(commits) > maximum {
+		warnTruncated(from, to, len(commits), maximum)
+		commits = keepNewest(commits, maximum, func(commit gitlabCommit) time.Time {
+			return commit.CommittedDate
+		})
+	}
+
+	return commits, nil
+}
+
+// DefaultBranch queries the project's default branch
+func (s *gitlabService) DefaultBranch(ctx context.Context) (string, error) {
+	projectContext, cancel := s.contextual.CreateContext(&ctx)
+	defer cancel()
+
+	// see https://docs.gitlab.com/api/projects/#get-a-single-project
+	project := new(struct {
+		DefaultBranch string `json:"default_branch"`
+	})
+	if _, err := s.api.get(projectContext, strings.TrimSuffix(s.projectPath(""), "/"), nil, project); err != nil {
+		return "", err
+	}
+	return project.DefaultBranch, nil
+}
+
+// projectPath creates a path relative to the API for the configured project, where owner may include subgroups
+func (s *gitlabService) projectPath(resource string) string {
+	return "projects/" + url.PathEscape(s.config.Owner+"/"+s.config.Repo) + "/" + resource
+}
+
+func (s *gitlabService) convertToChangeItem(commit gitlabCommit, urls *model.URLBuilder, ctx *context.Context) (*model.ChangeItem, error) {
+	if len(commit.ParentIDs) > 1 {
+		return nil, nil // Skip merge commits
+	}
+
+	title, _, _ := strings.Cut(commit.Message, "\n")
+	if s.config.ShouldExcludeByText(&title) {
+		return nil, nil
+	}
+
+	grouping := s.config.FindGroup(commit.Message)
+	if s.config.ShouldExcludeByText(grouping) {
+		return nil, nil
+	}
+
+	ci := &model.ChangeItem{
+		AuthorRaw:        &commit.AuthorName,
+		CommitMessageRaw: &commit.Message,
+		DateRaw:          &commit.CommittedDate,
+		CommitHashRaw:    &commit.ID,
+		CommitURLRaw:     &commit.WebURL,
+		GroupRaw:         grouping,
+	}
+
+	if urls != nil {
+		commitURL := urls.CommitURL(ci.CommitHash())
+		ci.CommitURLRaw = &commitURL
+	}
+
+	mergeRequest, exclude, err := s.shouldExcludeViaMergeRequest(commit.ID, ctx)
+	if err != nil {
+		return nil, &CommitError{SHA: commit.ID, Err: fmt.Errorf("unable to query merge requests: %w", err)}
+	}
+	if exclude {
+		return nil, nil
+	}
+
+	if mergeRequest != nil {
+		isPull := true
+		ci.IsPullRaw = &isPull
+		ci.PullSourceRaw = model.PullFromAssociation.Ptr()
+		ci.PullURLRaw = &mergeRequest.WebURL
+		if urls != nil {
+			pullURL := urls.PullURL(strconv.Itoa(mergeRequest.IID))
+			ci.PullURLRaw = &pullURL
+		}
+		ci.AuthorRaw = &mergeRequest.Author.Username
+		ci.AuthorURLRaw = &mergeRequest.Author.WebURL
+
+		mergeCommit := mergeRequest.MergeCommitSHA
+		if mergeCommit == "" {
+			// fast-forward merges of squashed merge requests have no merge commit
+			mergeCommit = mergeRequest.SquashCommitSHA
+		}
+		ci.PullNumberRaw = &mergeRequest.IID
+		ci.PullTitleRaw = &mergeRequest.Title
+		ci.PullAuthorRaw = &mergeRequest.Author.Username
+		ci.PullAuthorURLRaw = &mergeRequest.Author.WebURL
+		ci.MergedAtRaw = mergeRequest.MergedAt
+		ci.LabelsRaw = mergeRequest.Labels
+		if mergeCommit != "" {
+			ci.MergeCommitRaw = &mergeCommit
+		}
+	}
+
+	return ci, nil
+}
+
+// shouldExcludeViaMergeRequest finds the merge request which merged sha, evaluating its title and labels against exclusion rules.
+// Commits associated only with closed or open merge requests are treated as direct commits.
+func (s *gitlabService) shouldExcludeViaMergeRequest(sha string, parent *context.Context) (*gitlabMergeRequest, bool, error) {
+	timeout, cancel := s.contextual.CreateContext(parent)
+	defer cancel()
+
+	// see https://docs.gitlab.com/api/commits/#list-merge-requests-associated-with-a-commit
+	var mergeRequests []gitlabMergeRequest
+	if _, err := s.api.get(timeout, s.projectPath("repository/commits/"+url.PathEscape(sha)+"/merge_requests"), nil, &mergeRequests); err != nil {
+		return nil, false, err
+	}
+
+	var mergeRequest *gitlabMergeRequest
+	for i := range mergeRequests {
+		if mergeRequests[i].State == "merged" {
+			mergeRequest = &mergeRequests[i]
+			break
+		}
+	}
+	if mergeRequest == nil {
+		return nil, false, nil
+	}
+
+	if s.config.ShouldExcludeByText(&mergeRequest.Title) {
+		return mergeRequest, true, nil
+	}
+	for i := range mergeRequest.Labels {
+		if s.config.ShouldExcludeByText(&mergeRequest.Labels[i]) {
+			return mergeRequest, true, nil
+		}
+	}
+	return mergeRequest, false, nil
+}
@@ -0,0 +1,111 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// newGitLabServer is a stand-in for the GitLab REST API serving a small comparison of group/sub/project
+func newGitLabServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return newAPIServer(t, "PRIVATE-TOKEN", "secret", map[string]http.HandlerFunc{
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/compare": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "v1.0.0", r.URL.Query().Get("from"))
+			assert.Equal(t, "v1.1.0", r.URL.Query().Get("to"))
+			_, _ = w.Write([]byte(`{"commits": [
+				{"id": "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "title": "Add widgets", "message": "Add widgets\n\nDetails", "author_name": "Jim Schubert",
+				 "committed_date": "2026-01-02T10:00:00Z", "web_url": "https://gitlab.example.com/group/sub/project/-/commit/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "parent_ids": ["0000000000000000000000000000000000000000"]},
+				{"id": "bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "title": "Fix gadgets", "message": "Fix gadgets", "author_name": "Jim Schubert",
+				 "committed_date": "2026-01-03T10:00:00Z", "web_url": "https://gitlab.example.com/group/sub/project/-/commit/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb", "parent_ids": ["aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"]},
+				{"id": "cccccccccccccccccccccccccccccccccccccccc", "title": "Experimental gizmo", "message": "Experimental gizmo", "author_name": "Jim Schubert",
+				 "committed_date": "2026-01-04T10:00:00Z", "web_url": "https://gitlab.example.com/group/sub/project/-/commit/cccccccccccccccccccccccccccccccccccccccc", "parent_ids": ["bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb"]},
+				{"id": "dddddddddddddddddddddddddddddddddddddddd", "title": "Merge branch 'gadgets' into 'main'", "message": "Merge branch 'gadgets' into 'main'", "author_name": "Jim Schubert",
+				 "committed_date": "2026-01-05T10:00:00Z", "web_url": "https://gitlab.example.com/group/sub/project/-/commit/dddddddddddddddddddddddddddddddddddddddd", "parent_ids": ["aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", "cccccccccccccccccccccccccccccccccccccccc"]}
+			]}`))
+		},
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/commits/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa/merge_requests": respond(`[
+			{"iid": 2, "title": "Add widgets (superseded)", "state": "closed", "labels": [], "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/2", "author": {"username": "someone", "web_url": "https://gitlab.example.com/someone"}}
+		]`),
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/commits/bbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbbb/merge_requests": respond(`[
+			{"iid": 3, "title": "Fix gadgets (draft)", "state": "closed", "labels": [], "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/3", "author": {"username": "someone", "web_url": "https://gitlab.example.com/someone"}},
+			{"iid": 4, "title": "Fix gadgets", "state": "merged", "labels": ["bug"], "merged_at": "2026-01-06T10:00:00Z", "merge_commit_sha": "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/4", "author": {"username": "octocat", "web_url": "https://gitlab.example.com/octocat"}}
+		]`),
+		"/api/v4/projects/group%2Fsub%2Fproject/repository/commits/cccccccccccccccccccccccccccccccccccccccc/merge_requests": respond(`[
+			{"iid": 5, "title": "Experimental gizmo", "state": "merged", "labels": ["do not release"], "web_url": "https://gitlab.example.com/group/sub/project/-/merge_requests/5", "author": {"username": "octocat", "web_url": "https://gitlab.example.com/octocat"}}
+		]`),
+	})
+}
+
+func Test_gitlabService_Changes(t *testing.T) {
+	server := newGitLabServer(t)
+	enterprise := "https://gitlab.example.com"
+	config := &model.Config{
+		Owner:      "group/sub",
+		Repo:       "project",
+		Provider:   model.GitLab.Ptr(),
+		Enterprise: &enterprise,
+		Exclude:    []string{"do not release"},
+	}
+
+	store, err := NewGitLabService(server.URL, "secret", server.Client())
+	assert.NoError(t, err)
+
+	items, err := collect(t, store.WithConfig(config), "v1.0.0", "v1.1.0")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2, "merge commits and commits from excluded merge requests are skipped")
+
+	byTitle := make(map[string]model.ChangeItem)
+	for _, item := range items {
+		byTitle[item.Title()] = item
+	}
+
+	direct := byTitle["Add widgets"]
+	assert.False(t, direct.IsPull(), "a closed merge request didn't merge the commit")
+	assert.Equal(t, "Jim Schubert", direct.Author())
+	assert.Equal(t, "https://gitlab.example.com/group/sub/project/-/commit/aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa", direct.CommitURL())
+
+	merged := byTitle["Fix gadgets"]
+	assert.True(t, merged.IsPull())
+	assert.Equal(t, "https://gitlab.example.com/group/sub/project/-/merge_requests/4", merged.PullURL())
+	assert.Equal(t, "octocat", merged.Author())
+	assert.Equal(t, "https://gitlab.example.com/octocat", merged.AuthorURL())
+	assert.Equal(t, "2026-01-03T10:00:00Z", merged.Date().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, 4, merged.PullNumber())
+	assert.Equal(t, []string{"bug"}, merged.Labels())
+	assert.Equal(t, "2026-01-06T10:00:00Z", merged.MergedAt().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "eeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeeee", merged.MergeCommit())
+	assert.Equal(t, model.PullFromAssociation, merged.PullSource())
+}
+
+func Test_gitlabService_Changes_error(t *testing.T) {
+	server := newGitLabServer(t)
+	config := &model.Config{Owner: "group/sub", Repo: "missing"}
+
+	store, err := NewGitLabService(server.URL+"/api/v4/", "", server.Client())
+	assert.NoError(t, err)
+
+	_, err = collect(t, store.WithConfig(config), "v1.0.0", "v1.1.0")
+	var responseError *ResponseError
+	if assert.ErrorAs(t, err, &responseError) {
+		assert.Equal(t, http.StatusNotFound, responseError.StatusCode)
+	}
+}
@@ -17,15 +17,12 @@ package service
 import (
 	"context"
 	"errors"
-	"io"
-	"net/url"
-	"os"
-	"path"
-	"strconv"
+	"fmt"
+	"iter"
 	"strings"
-	"sync"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/google/go-github/v29/github"
 	log "github.com/sirupsen/logrus"
@@ -39,17 +36,18 @@ type gitService struct {
 }
 
 func NewLocalGitService() Store {
-	service := &gitService{}
+	service := &gitService{contextual: newContextual(nil)}
 	return service
 }
 
 func (s *gitService) WithClient(client *github.Client) Store {
-	s.contextual = newContextual(client)
+	s.contextual = newContextual(client).withConfig(s.config)
 	return s
 }
 
 func (s *gitService) WithConfig(config *model.Config) Store {
 	s.config = config
+	s.contextual = s.contextual.withConfig(config)
 	return s
 }
 
@@ -57,105 +55,235 @@ func (s *gitService) GetContextual() *Contextual {
 	return s.contextual
 }
 
-//goland:noinspection ALL
-func (s *gitService) Process(parentContext *context.Context, wg *sync.WaitGroup, ciChan chan *model.ChangeItem, from string, to string) error {
-	wg.Add(1)
-	defer wg.Done()
+// Changes walks the local repository for commits, yielding each as a ChangeItem
+func (s *gitService) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	list := func(ctx *context.Context) ([]*object.Commit, error) {
+		return s.listCommits(*ctx, from, to)
+	}
+	urls := urlBuilder(s.config)
+	convert := func(commit *object.Commit, ctx *context.Context) (*model.ChangeItem, error) {
+		return s.convertToChangeItem(commit, urls, ctx)
+	}
+	return streamCommits(ctx, s.contextual, s.config.GetConcurrency(), list, convert)
+}
 
-	contextual := s.contextual
+// listCommits opens the configured repository, returning the commits reachable from 'to' but not from 'from'
+func (s *gitService) listCommits(ctx context.Context, from string, to string) ([]*object.Commit, error) {
+	repo, err := s.openRepository()
+	if err != nil {
+		return nil, err
+	}
 
-	_, cancel := contextual.CreateContext(parentContext)
-	defer cancel()
+	var fromCommit *object.Commit
+	if from != "" {
+		fromCommit, err = resolveCommit(repo, from)
+		if err != nil {
+			log.WithFields(log.Fields{"error": err, "from": from}).Error("Unable to resolve 'from' revision.")
+			return nil, err
+		}
+	}
 
-	dir, err := os.Getwd()
+	startCommit, err := resolveCommit(repo, to)
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "to": to}).Error("Unable to resolve 'to' revision.")
+		return nil, err
+	}
+
+	commits, err := commitsInRange(ctx, fromCommit, startCommit)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"from": from,
+			"to":   startCommit.Hash.String(),
+		}).Error("Failed while processing commits.")
+		return nil, err
+	}
+
+	return commits, nil
+}
+
+// openRepository opens the repository enclosing the configured path
+func (s *gitService) openRepository() (*git.Repository, error) {
+	dir, err := s.config.GetPath()
 	if err != nil {
 		log.WithFields(log.Fields{"error": err}).Error("Unable to determine current directory for repository.")
-		return err
+		return nil, err
+	}
+
+	// DetectDotGit walks up from dir to the enclosing repository; EnableDotGitCommonDir supports linked worktrees
+	repo, err := git.PlainOpenWithOptions(dir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if err != nil {
+		log.WithFields(log.Fields{"error": err, "path": dir}).Error("Unable to open directory as a git repository.")
+		return nil, err
 	}
+	return repo, nil
+}
 
-	repo, err := git.PlainOpen(dir)
+// DefaultBranch returns the branch referenced by the repository's symbolic HEAD, or HEAD itself when detached
+func (s *gitService) DefaultBranch(ctx context.Context) (string, error) {
+	repo, err := s.openRepository()
 	if err != nil {
-		log.WithFields(log.Fields{"error": err}).Error("Unable to open current directory as a git repository.")
-		return err
+		return "", err
 	}
 
-	//noinspection GoNilness
-	fromTag, err := repo.Tag(from)
+	head, err := repo.Reference(plumbing.HEAD, false)
 	if err != nil {
-		log.WithFields(log.Fields{"error": err, "from": from}).Error("Unable to find 'from' tag.")
-		return err
+		return "", err
 	}
+	if head.Type() != plumbing.SymbolicReference {
+		log.Debug("HEAD is detached, so there's no branch to end at.")
+		return plumbing.HEAD.String(), nil
+	}
+	return head.Target().Short(), nil
+}
 
-	toTag, err := repo.Tag(to)
-	if toTag == nil || err != nil {
-		log.WithFields(log.Fields{"error": err, "to": to}).Error("Unable to find 'to' tag.")
-		return err
+// Ref resolves revision to its commit, using the tagger's date and message when revision names an annotated tag
+func (s *gitService) Ref(ctx context.Context, revision string) (*model.RefInfo, error) {
+	repo, err := s.openRepository()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := resolveCommit(repo, revision)
+	if err != nil {
+		return nil, err
 	}
 
-	startCommit, err := repo.CommitObject(toTag.Hash())
+	info := &model.RefInfo{Name: revision, Type: model.CommitRef, SHA: commit.Hash.String(), Date: commit.Committer.When}
+	if ref, err := repo.Tag(revision); err == nil {
+		info.Type = model.TagRef
+		if tag, err := repo.TagObject(ref.Hash()); err == nil {
+			info.Date = tag.Tagger.When
+			info.Tagger = tag.Tagger.Name
+			info.TaggerEmail = tag.Tagger.Email
+			info.Annotation = strings.TrimSpace(tag.Message)
+		}
+	} else if _, err := repo.Reference(plumbing.NewBranchReferenceName(revision), false); err == nil {
+		info.Type = model.BranchRef
+	}
+	return info, nil
+}
+
+// Tags lists the repository's tags, peeling annotated tags to their commit
+func (s *gitService) Tags(ctx context.Context) ([]Tag, error) {
+	repo, err := s.openRepository()
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// NewCommitIterBSF returns a CommitIter that walks the commit history,
-	// starting at the given commit and visiting its parents in pre-order.
-	err = object.NewCommitIterBSF(startCommit, nil, nil).ForEach(func(commit *object.Commit) error {
-		if commit.Hash == fromTag.Hash() {
-			return io.EOF
+	refs, err := repo.Tags()
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]Tag, 0)
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		commit, err := resolveCommit(repo, ref.Name().String())
+		if err != nil {
+			// tags may reference trees or blobs, which can't be releases
+			log.WithFields(log.Fields{"tag": ref.Name().Short(), "error": err}).Debug("skipping tag which doesn't reference a commit")
+			return ctx.Err()
 		}
-		wg.Add(1)
-		go func(commit *object.Commit) {
-			newContext, newCancel := contextual.CreateContext(parentContext)
-			defer newCancel()
-			s.convertToChangeItem(commit, ciChan, wg, &newContext)
-		}(commit)
-		return nil
+		tags = append(tags, Tag{Name: ref.Name().Short(), SHA: commit.Hash.String()})
+		return ctx.Err()
 	})
+	if err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// Precedes determines whether revision is an ancestor of to
+func (s *gitService) Precedes(ctx context.Context, revision string, to string) (bool, error) {
+	repo, err := s.openRepository()
+	if err != nil {
+		return false, err
+	}
+
+	ancestor, err := resolveCommit(repo, revision)
+	if err != nil {
+		return false, err
+	}
+	descendant, err := resolveCommit(repo, to)
+	if err != nil {
+		return false, err
+	}
+	if ancestor.Hash == descendant.Hash {
+		return false, nil
+	}
+	return ancestor.IsAncestor(descendant)
+}
+
+// commitsInRange returns the commits reachable from 'to' but not from 'from', matching the semantics of `git log from..to`.
+// Commits reachable from both ends are exactly the ancestors of their merge base(s), so that history is marked as seen
+// before walking from 'to'; the walk then stops wherever it meets shared history, regardless of which branch 'from' sits on.
+// A nil 'from' shares no history, so every commit reachable from 'to' is returned. Both walks stop early once ctx is done.
+func commitsInRange(ctx context.Context, from *object.Commit, to *object.Commit) ([]*object.Commit, error) {
+	shared := make(map[plumbing.Hash]bool)
+	if from != nil {
+		bases, err := from.MergeBase(to)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, base := range bases {
+			err = object.NewCommitPreorderIter(base, shared, nil).ForEach(func(commit *object.Commit) error {
+				shared[commit.Hash] = true
+				return ctx.Err()
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	if err != nil && !errors.Is(err, io.EOF) {
 		log.WithFields(log.Fields{
-			"from": fromTag.Hash().String(),
-			"to":   toTag.Hash().String(),
-		}).Error("Failed while processing commits.")
-		return err
+			"from":   from.Hash.String(),
+			"to":     to.Hash.String(),
+			"bases":  len(bases),
+			"shared": len(shared),
+		}).Debug("determined shared history for commit range")
 	}
 
-	return nil
+	commits := make([]*object.Commit, 0)
+	err := object.NewCommitPreorderIter(to, shared, nil).ForEach(func(commit *object.Commit) error {
+		commits = append(commits, commit)
+		return ctx.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return commits, nil
 }
 
-func (s *gitService) convertToChangeItem(commit *object.Commit, ch chan *model.ChangeItem, wg *sync.WaitGroup, ctx *context.Context) {
-	defer wg.Done()
+// resolveCommit resolves any git revision (tag, branch, SHA, or expressions such as HEAD~5) to the commit it references.
+// Annotated tags are peeled to their target commit.
+func resolveCommit(repo *git.Repository, revision string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(revision))
+	if err != nil {
+		return nil, &RefNotFoundError{Ref: revision, Err: err}
+	}
 
+	commit, err := repo.CommitObject(*hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, &RefNotFoundError{Ref: revision, Err: err}
+	}
+	return commit, err
+}
+
+func (s *gitService) convertToChangeItem(commit *object.Commit, urls *model.URLBuilder, ctx *context.Context) (*model.ChangeItem, error) {
 	// Early returns to reduce nesting
 	if commit.NumParents() > 1 {
-		return // Skip merge commits
+		return nil, nil // Skip merge commits
 	}
 
 	if s.shouldExcludeViaRepositoryCommit(commit) {
-		return
+		return nil, nil
 	}
 
 	grouping := s.config.FindGroup(commit.Message)
 	if s.config.ShouldExcludeByText(grouping) {
-		return
-	}
-
-	// URL creation is duplicated with GetGitURLs, this could be moved elsewhere to reduce duplication
-	gh := "https://github.com"
-	if s.config.Enterprise != nil {
-		gh = strings.TrimSuffix(*s.config.Enterprise, "/api")
-	}
-	baseURL, err := url.Parse(gh)
-	if err != nil {
-		log.WithFields(log.Fields{"error": err, "url": gh}).Warn("Unable to parse base URL for commit links")
-		return
+		return nil, nil
 	}
 
 	hash := commit.Hash.String()
-	// Create a copy to avoid mutating the shared baseURL
-	u := *baseURL
-	u.Path = path.Join(u.Path, s.config.Owner, s.config.Repo, "commit", hash)
-	commitLocation := u.String()
 	t := &commit.Committer.When
 
 	ci := &model.ChangeItem{
@@ -163,34 +291,47 @@ func (s *gitService) convertToChangeItem(commit *object.Commit, ch chan *model.C
 		CommitMessageRaw: &commit.Message,
 		DateRaw:          t,
 		CommitHashRaw:    &hash,
-		CommitURLRaw:     &commitLocation,
 		GroupRaw:         grouping,
 	}
 
-	applyPullPropertiesChangeItem(ci)
-
-	if !ci.IsPull() {
-		ch <- ci
-		return
+	if urls != nil {
+		commitLocation := urls.CommitURL(hash)
+		ci.CommitURLRaw = &commitLocation
 	}
 
-	pullId, e := ci.PullID()
-	if e != nil {
-		// In the unlikely case that an unexpected pull url is provided by GitHub API, just emit the change item
-		ch <- ci
-		return
+	contextual := s.contextual
+	if s.isOffline() {
+		// Without an API client, pull request details are limited to what can be derived from the commit itself
+		contextual = nil
 	}
 
-	pr, _ := strconv.Atoi(pullId)
-	pullRequest, exclude := shouldExcludeViaPullAttributes(pr, s.contextual, ctx, s.config)
+	pullRequest, exclude, err := resolvePullRequest(ci, urls, contextual, ctx, s.config)
+	if err != nil {
+		return nil, &CommitError{SHA: hash, Err: fmt.Errorf("unable to query pull request: %w", err)}
+	}
 	if exclude {
-		return
+		return nil, nil
 	}
 
-	ci.PullURLRaw = pullRequest.HTMLURL
+	if pullRequest == nil {
+		return ci, nil
+	}
+
+	if ci.PullURL() == "" {
+		ci.PullURLRaw = pullRequest.HTMLURL
+	}
 	ci.AuthorURLRaw = pullRequest.GetUser().HTMLURL
 	ci.AuthorRaw = pullRequest.GetUser().Login
-	ch <- ci
+	return ci, nil
+}
+
+// isOffline determines whether the API may be queried for supplemental data such as pull request labels
+func (s *gitService) isOffline() bool {
+	if s.config != nil && s.config.GetOffline() {
+		return true
+	}
+
+	return s.contextual == nil || s.contextual.GetClient() == nil
 }
 
 func (s *gitService) shouldExcludeViaRepositoryCommit(commit *object.Commit) bool {
@@ -0,0 +1,406 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// testRepo builds small repositories on the fly, writing commit objects directly so arbitrary histories can be created
+type testRepo struct {
+	t    *testing.T
+	dir  string
+	repo *git.Repository
+	tree plumbing.Hash
+	when time.Time
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(tree); err != nil {
+		t.Fatal(err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testRepo{t: t, dir: dir, repo: repo, tree: treeHash, when: time.Unix(1583008420, 0)}
+}
+
+// commit writes a commit with the given parents and returns its hash
+func (r *testRepo) commit(message string, parents ...plumbing.Hash) plumbing.Hash {
+	r.t.Helper()
+	r.when = r.when.Add(time.Minute)
+	signature := object.Signature{Name: "Jim Schubert", Email: "jim@example.com", When: r.when}
+	c := &object.Commit{
+		Author:       signature,
+		Committer:    signature,
+		Message:      message,
+		TreeHash:     r.tree,
+		ParentHashes: parents,
+	}
+	obj := r.repo.Storer.NewEncodedObject()
+	if err := c.Encode(obj); err != nil {
+		r.t.Fatal(err)
+	}
+	hash, err := r.repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return hash
+}
+
+// branch points refs/heads/name at hash
+func (r *testRepo) branch(name string, hash plumbing.Hash) {
+	r.t.Helper()
+	ref := plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), hash)
+	if err := r.repo.Storer.SetReference(ref); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// tag creates a lightweight tag, or an annotated tag when message is non-empty
+func (r *testRepo) tag(name string, hash plumbing.Hash, message string) {
+	r.t.Helper()
+	var opts *git.CreateTagOptions
+	if message != "" {
+		opts = &git.CreateTagOptions{
+			Tagger:  &object.Signature{Name: "Jim Schubert", Email: "jim@example.com", When: r.when},
+			Message: message,
+		}
+	}
+	if _, err := r.repo.CreateTag(name, hash, opts); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+func Test_resolveCommit(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit("first")
+	second := r.commit("second", first)
+	third := r.commit("third", second)
+	r.branch("master", third)
+	r.branch("release/1.x", second)
+	r.tag("v1.0.0", first, "")
+	r.tag("v1.1.0", second, "Release 1.1.0")
+
+	tests := []struct {
+		name     string
+		revision string
+		want     plumbing.Hash
+		wantErr  bool
+	}{
+		{"lightweight tag", "v1.0.0", first, false},
+		{"annotated tag is peeled to commit", "v1.1.0", second, false},
+		{"branch", "master", third, false},
+		{"branch with slash", "release/1.x", second, false},
+		{"full sha", third.String(), third, false},
+		{"HEAD", "HEAD", third, false},
+		{"ancestry expression", "HEAD~2", first, false},
+		{"parent of tag", "v1.1.0^", first, false},
+		{"unknown revision", "v9.9.9", plumbing.ZeroHash, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := resolveCommit(r.repo, tt.revision)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, ErrRefNotFound)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got.Hash)
+		})
+	}
+}
+
+func Test_gitService_DefaultBranch(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit("first")
+	r.branch("trunk", first)
+
+	tests := []struct {
+		name string
+		head *plumbing.Reference
+		want string
+	}{
+		{"symbolic HEAD", plumbing.NewSymbolicReference(plumbing.HEAD, plumbing.NewBranchReferenceName("trunk")), "trunk"},
+		{"detached HEAD", plumbing.NewHashReference(plumbing.HEAD, first), "HEAD"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.NoError(t, r.repo.Storer.SetReference(tt.head))
+			store := NewLocalGitService().WithConfig(&model.Config{Path: &r.dir}).(BranchSource)
+
+			got, err := store.DefaultBranch(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gitService_Ref(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit("first")
+	second := r.commit("second", first)
+	r.branch("main", second)
+	r.tag("v1.0.0", first, "")
+	r.when = r.when.Add(time.Hour)
+	r.tag("v1.1.0", second, "Release 1.1.0\n")
+	commitDate := func(hash plumbing.Hash) time.Time {
+		commit, _ := r.repo.CommitObject(hash)
+		return commit.Committer.When
+	}
+
+	tests := []struct {
+		name     string
+		revision string
+		want     model.RefInfo
+	}{
+		{"annotated tag", "v1.1.0", model.RefInfo{Type: model.TagRef, SHA: second.String(), Date: r.when, Tagger: "Jim Schubert", TaggerEmail: "jim@example.com", Annotation: "Release 1.1.0"}},
+		{"lightweight tag", "v1.0.0", model.RefInfo{Type: model.TagRef, SHA: first.String(), Date: commitDate(first)}},
+		{"branch", "main", model.RefInfo{Type: model.BranchRef, SHA: second.String(), Date: commitDate(second)}},
+		{"commit", "main~1", model.RefInfo{Type: model.CommitRef, SHA: first.String(), Date: commitDate(first)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewLocalGitService().WithConfig(&model.Config{Path: &r.dir}).(RefSource)
+			got, err := store.Ref(t.Context(), tt.revision)
+			if !assert.NoError(t, err) {
+				return
+			}
+			tt.want.Name = tt.revision
+			assert.True(t, tt.want.Date.Equal(got.Date), "Date = %v, want %v", got.Date, tt.want.Date)
+			got.Date = tt.want.Date
+			assert.Equal(t, tt.want, *got)
+		})
+	}
+
+	_, err := NewLocalGitService().WithConfig(&model.Config{Path: &r.dir}).(RefSource).Ref(t.Context(), "v9.9.9")
+	assert.ErrorIs(t, err, ErrRefNotFound)
+}
+
+func Test_gitService_convertToChangeItem_offline(t *testing.T) {
+	background := context.Background()
+	offline := true
+	signature := object.Signature{Name: "Jim Schubert", When: time.Unix(1583008420, 0)}
+	tests := []struct {
+		name       string
+		config     *model.Config
+		message    string
+		wantIsPull bool
+		wantPull   string
+	}{
+		{
+			name:    "commit without pull reference",
+			config:  &model.Config{Owner: "jimschubert", Repo: "changelog"},
+			message: "Initial commit",
+		},
+		{
+			name:       "pull reference without client",
+			config:     &model.Config{Owner: "jimschubert", Repo: "changelog", Exclude: []string{"wip"}},
+			message:    "Add offline mode (#12)",
+			wantIsPull: true,
+			wantPull:   "https://github.com/jimschubert/changelog/pull/12",
+		},
+		{
+			name:       "pull reference with offline config",
+			config:     &model.Config{Owner: "jimschubert", Repo: "changelog", Offline: &offline},
+			message:    "Add offline mode (#12)",
+			wantIsPull: true,
+			wantPull:   "https://github.com/jimschubert/changelog/pull/12",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := gitService{
+				contextual: newContextual(nil),
+				config:     tt.config,
+			}
+			commit := &object.Commit{
+				Hash:      plumbing.NewHash("d707829d23b58326182c3c17fb5f52d275feda6b"),
+				Author:    signature,
+				Committer: signature,
+				Message:   tt.message,
+			}
+
+			ci, err := s.convertToChangeItem(commit, urlBuilder(tt.config), &background)
+			assert.NoError(t, err)
+			assert.NotNil(t, ci)
+			assert.Equal(t, "Jim Schubert", ci.Author())
+			assert.Equal(t, "https://github.com/jimschubert/changelog/commit/d707829d23b58326182c3c17fb5f52d275feda6b", ci.CommitURL())
+			assert.Equal(t, tt.wantIsPull, ci.IsPull())
+			assert.Equal(t, tt.wantPull, ci.PullURL())
+		})
+	}
+}
+
+func Test_commitsInRange(t *testing.T) {
+	// A - B - C - M - E        (main)
+	//      \     /
+	//       F - G              (feature, merged into main at M)
+	//      \
+	//       X - Y              (side, never merged)
+	r := newTestRepo(t)
+	a := r.commit("A")
+	b := r.commit("B", a)
+	c := r.commit("C", b)
+	f := r.commit("F", b)
+	g := r.commit("G", f)
+	m := r.commit("M", c, g)
+	e := r.commit("E", m)
+	x := r.commit("X", b)
+	y := r.commit("Y", x)
+	orphan := r.commit("orphan")
+
+	tests := []struct {
+		name string
+		from plumbing.Hash
+		to   plumbing.Hash
+		want []string
+	}{
+		{"linear first-parent range", b, c, []string{"C"}},
+		{"range spanning a merge", c, e, []string{"E", "M", "F", "G"}},
+		{"from on merged side branch", g, e, []string{"E", "M", "C"}},
+		{"from on unmerged side branch", y, e, []string{"E", "M", "C", "F", "G"}},
+		{"to on side branch", e, y, []string{"X", "Y"}},
+		{"same commit", e, e, []string{}},
+		{"from is descendant of to", e, b, []string{}},
+		{"unrelated histories", orphan, c, []string{"A", "B", "C"}},
+		{"from the repository root", plumbing.ZeroHash, e, []string{"A", "B", "C", "F", "G", "M", "E"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var from *object.Commit
+			if !tt.from.IsZero() {
+				var err error
+				from, err = r.repo.CommitObject(tt.from)
+				assert.NoError(t, err)
+			}
+			to, err := r.repo.CommitObject(tt.to)
+			assert.NoError(t, err)
+
+			commits, err := commitsInRange(t.Context(), from, to)
+			assert.NoError(t, err)
+
+			got := make([]string, 0, len(commits))
+			for _, commit := range commits {
+				got = append(got, commit.Message)
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+// collect runs Changes to completion, gathering all emitted change items
+func collect(t *testing.T, store Store, from string, to string) ([]model.ChangeItem, error) {
+	t.Helper()
+	items := make([]model.ChangeItem, 0)
+	errs := make([]error, 0)
+	for ci, err := range store.Changes(t.Context(), from, to) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		items = append(items, ci)
+	}
+	return items, errors.Join(errs...)
+}
+
+func Test_gitService_Changes_path(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit("first")
+	second := r.commit("second", first)
+	r.branch("master", r.commit("third", second))
+	r.tag("v1.0.0", first, "")
+
+	nested := filepath.Join(r.dir, "nested", "dir")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    []string
+		wantErr bool
+	}{
+		{"repository root", r.dir, []string{"second", "third"}, false},
+		{"directory within repository", nested, []string{"second", "third"}, false},
+		{"directory outside of a repository", t.TempDir(), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := &model.Config{Owner: "jimschubert", Repo: "changelog", Path: &tt.path}
+			store := NewLocalGitService().WithClient(nil).WithConfig(config)
+
+			items, err := collect(t, store, "v1.0.0", "master")
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got := make([]string, 0, len(items))
+			for _, item := range items {
+				got = append(got, item.Title())
+			}
+			assert.ElementsMatch(t, tt.want, got)
+		})
+	}
+}
+
+func Test_gitService_Changes_invalidURLs(t *testing.T) {
+	r := newTestRepo(t)
+	first := r.commit("first")
+	second := r.commit("second", first)
+	r.branch("master", r.commit("third", second))
+	r.tag("v1.0.0", first, "")
+
+	hook := logtest.NewGlobal()
+	defer hook.Reset()
+	config := &model.Config{Owner: "jimschubert", Repo: "changelog", Path: &r.dir, URLs: &model.URLConfig{Scheme: "svn"}}
+	items, err := collect(t, NewLocalGitService().WithConfig(config), "v1.0.0", "master")
+	assert.NoError(t, err)
+	assert.Len(t, items, 2)
+
+	warnings := 0
+	for _, entry := range hook.AllEntries() {
+		if entry.Message == "Unable to create links for commits and pull requests" {
+			warnings++
+		}
+	}
+	assert.Equal(t, 1, warnings, "an invalid urls config is reported once per range rather than per commit")
+}
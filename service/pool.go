@@ -0,0 +1,112 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// listFunc queries a store for the commits of a range
+type listFunc[T any] func(ctx *context.Context) ([]T, error)
+
+// convertFunc converts a commit to a ChangeItem, returning a nil ChangeItem when the commit is skipped or excluded
+type convertFunc[T any] func(commit T, ctx *context.Context) (*model.ChangeItem, error)
+
+// streamCommits lists commits, then converts them using at most concurrency goroutines. ChangeItems are yielded in the
+// order of commits regardless of which conversion completes first. A failure to list commits, or cancellation of ctx, ends
+// the sequence with that error. When the consumer stops early, outstanding conversions are cancelled and awaited, so no
+// goroutines outlive the iteration.
+func streamCommits[T any](ctx context.Context, contextual *Contextual, concurrency int, list listFunc[T], convert convertFunc[T]) iter.Seq2[model.ChangeItem, error] {
+	return func(yield func(model.ChangeItem, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		wg := sync.WaitGroup{}
+		defer func() {
+			cancel()
+			wg.Wait()
+		}()
+
+		commits, err := list(&ctx)
+		if err != nil {
+			yield(model.ChangeItem{}, err)
+			return
+		}
+
+		workers := max(1, min(concurrency, len(commits)))
+		log.WithFields(log.Fields{"commits": len(commits), "workers": workers}).Debug("processing commits")
+
+		type result struct {
+			ci  *model.ChangeItem
+			err error
+		}
+		results := make([]result, len(commits))
+		done := make([]chan struct{}, len(commits))
+		for i := range done {
+			done[i] = make(chan struct{})
+		}
+
+		// every commit is handed to a worker, even once cancelled, so each done channel is eventually closed
+		jobs := make(chan int)
+		wg.Add(workers + 1)
+		go func() {
+			defer wg.Done()
+			defer close(jobs)
+			for i := range commits {
+				jobs <- i
+			}
+		}()
+
+		for range workers {
+			go func() {
+				defer wg.Done()
+				for i := range jobs {
+					if err := ctx.Err(); err != nil {
+						results[i].err = err
+					} else {
+						commitContext, commitCancel := contextual.CreateContext(&ctx)
+						results[i].ci, results[i].err = convert(commits[i], &commitContext)
+						commitCancel()
+					}
+					close(done[i])
+				}
+			}()
+		}
+
+		for i := range commits {
+			<-done[i]
+			// conversions completed before cancellation are still yielded; those it interrupted end the sequence
+			if err := ctx.Err(); err != nil && errors.Is(results[i].err, err) {
+				yield(model.ChangeItem{}, err)
+				return
+			}
+			switch {
+			case results[i].err != nil:
+				if !yield(model.ChangeItem{}, results[i].err) {
+					return
+				}
+			case results[i].ci != nil:
+				if !yield(*results[i].ci, nil) {
+					return
+				}
+			}
+		}
+	}
+}
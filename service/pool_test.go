@@ -0,0 +1,169 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// listOf lists commits 0 through n-1
+func listOf(n int) listFunc[int] {
+	return func(ctx *context.Context) ([]int, error) {
+		commits := make([]int, n)
+		for i := range commits {
+			commits[i] = i
+		}
+		return commits, nil
+	}
+}
+
+func Test_streamCommits(t *testing.T) {
+	tests := []struct {
+		name        string
+		commits     int
+		concurrency int
+		wantWorkers int32
+	}{
+		{"bounded by concurrency", 50, 4, 4},
+		{"bounded by commits", 3, 8, 3},
+		{"at least one worker", 5, 0, 1},
+		{"no commits", 0, 4, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var active, peak atomic.Int32
+			convert := func(commit int, ctx *context.Context) (*model.ChangeItem, error) {
+				n := active.Add(1)
+				defer active.Add(-1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				// later commits finish first, so ordering can't be an accident of completion order
+				time.Sleep(time.Duration(tt.commits-commit) * 100 * time.Microsecond)
+				hash := strconv.Itoa(commit)
+				switch commit % 5 {
+				case 3:
+					return nil, &CommitError{SHA: hash, Err: errors.New("lookup failed")}
+				case 4:
+					return nil, nil
+				}
+				return &model.ChangeItem{CommitHashRaw: &hash}, nil
+			}
+
+			want := make([]string, 0)
+			for commit := range tt.commits {
+				switch commit % 5 {
+				case 3:
+					want = append(want, "error "+strconv.Itoa(commit))
+				case 4:
+				default:
+					want = append(want, strconv.Itoa(commit))
+				}
+			}
+
+			got := make([]string, 0)
+			for ci, err := range streamCommits(t.Context(), newContextual(nil), tt.concurrency, listOf(tt.commits), convert) {
+				if err != nil {
+					var commitError *CommitError
+					if assert.ErrorAs(t, err, &commitError) {
+						got = append(got, "error "+commitError.SHA)
+					}
+					continue
+				}
+				got = append(got, ci.CommitHash())
+			}
+
+			assert.Equal(t, want, got, "results are yielded in the order of commits, omitting nil conversions")
+			assert.LessOrEqual(t, peak.Load(), tt.wantWorkers)
+			if tt.commits > 0 {
+				assert.Positive(t, peak.Load())
+			}
+		})
+	}
+}
+
+func Test_streamCommits_listError(t *testing.T) {
+	listErr := errors.New("compare failed")
+	list := func(ctx *context.Context) ([]int, error) {
+		return nil, listErr
+	}
+	convert := func(commit int, ctx *context.Context) (*model.ChangeItem, error) {
+		t.Fatal("nothing is converted when listing fails")
+		return nil, nil
+	}
+
+	errs := make([]error, 0)
+	for _, err := range streamCommits(t.Context(), newContextual(nil), 4, list, convert) {
+		errs = append(errs, err)
+	}
+	assert.Equal(t, []error{listErr}, errs)
+}
+
+func Test_streamCommits_stopped(t *testing.T) {
+	var converted atomic.Int32
+	convert := func(commit int, ctx *context.Context) (*model.ChangeItem, error) {
+		converted.Add(1)
+		time.Sleep(time.Millisecond)
+		hash := strconv.Itoa(commit)
+		return &model.ChangeItem{CommitHashRaw: &hash}, nil
+	}
+
+	before := runtime.NumGoroutine()
+	for range streamCommits(t.Context(), newContextual(nil), 2, listOf(100), convert) {
+		// stop after the first item, as a strict run does after its first failure
+		break
+	}
+
+	assert.Less(t, converted.Load(), int32(100), "remaining commits are skipped")
+	assert.LessOrEqual(t, runtime.NumGoroutine(), before, "no goroutines outlive the iteration")
+}
+
+func Test_streamCommits_cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	convert := func(commit int, ctx *context.Context) (*model.ChangeItem, error) {
+		hash := strconv.Itoa(commit)
+		if commit == 1 {
+			cancel()
+			return nil, &CommitError{SHA: hash, Err: (*ctx).Err()}
+		}
+		return &model.ChangeItem{CommitHashRaw: &hash}, nil
+	}
+
+	items := 0
+	errs := make([]error, 0)
+	for _, err := range streamCommits(ctx, newContextual(nil), 1, listOf(10), convert) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		items++
+	}
+
+	assert.Equal(t, 1, items, "items converted before cancellation are yielded")
+	assert.Equal(t, []error{context.Canceled}, errs, "cancellation ends the sequence")
+}
@@ -0,0 +1,102 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"iter"
+	"sync"
+
+	"github.com/google/go-github/v29/github"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// Processor is the channel-based contract which preceded Store.Changes, retained so existing implementations can be
+// adapted via NewProcessorStore
+type Processor interface {
+	// Process queries the store and converts commits to a ChangeItem before sending to ciChan. Failures are only reported
+	// via the returned error. Work started by Process is tracked by wg; the caller receives from ciChan until wg is done.
+	Process(parentContext *context.Context, wg *sync.WaitGroup, ciChan chan *model.ChangeItem, from string, to string) error
+}
+
+type processorStore struct {
+	processor  Processor
+	config     *model.Config
+	contextual *Contextual
+}
+
+// NewProcessorStore adapts a Processor to the Store interface. The processor is responsible for its own configuration;
+// a client or config applied via WithClient or WithConfig is only retained.
+func NewProcessorStore(processor Processor) Store {
+	return &processorStore{processor: processor, contextual: newContextual(nil)}
+}
+
+// WithClient retains client for GetContextual
+func (s *processorStore) WithClient(client *github.Client) Store {
+	s.contextual = newContextual(client).withConfig(s.config)
+	return s
+}
+
+// WithConfig applies a Config instance to the Store
+func (s *processorStore) WithConfig(config *model.Config) Store {
+	s.config = config
+	s.contextual = s.contextual.withConfig(config)
+	return s
+}
+
+// GetContextual returns the context wrapper for this store
+func (s *processorStore) GetContextual() *Contextual {
+	return s.contextual
+}
+
+// Changes runs the processor, yielding what it sends to ciChan. When the consumer stops early, the processor's
+// context is cancelled and ciChan is drained until all of its work is done.
+func (s *processorStore) Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error] {
+	return func(yield func(model.ChangeItem, error) bool) {
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		ciChan := make(chan *model.ChangeItem)
+		wg := sync.WaitGroup{}
+
+		processErr := s.processor.Process(&ctx, &wg, ciChan, from, to)
+
+		done := make(chan struct{})
+		go func() {
+			wg.Wait()
+			close(done)
+		}()
+
+		// after a failure, or once the consumer stops, ciChan is still drained so no sender is left blocked
+		consuming := true
+		if processErr != nil {
+			yield(model.ChangeItem{}, processErr)
+			consuming = false
+			cancel()
+		}
+		for {
+			select {
+			case ci := <-ciChan:
+				if consuming && ci != nil && !yield(*ci, nil) {
+					consuming = false
+					cancel()
+				}
+			case <-done:
+				return
+			}
+		}
+	}
+}
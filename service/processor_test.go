@@ -0,0 +1,89 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// channelProcessor is a Processor in the style of stores written before Store.Changes, sending from one goroutine per commit
+type channelProcessor struct {
+	commits    int
+	processErr error
+	exited     atomic.Int32
+}
+
+func (p *channelProcessor) Process(parentContext *context.Context, wg *sync.WaitGroup, ciChan chan *model.ChangeItem, from string, to string) error {
+	if p.processErr != nil {
+		return p.processErr
+	}
+	for i := range p.commits {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer p.exited.Add(1)
+			if (*parentContext).Err() != nil {
+				return
+			}
+			hash := strconv.Itoa(i)
+			ciChan <- &model.ChangeItem{CommitHashRaw: &hash}
+		}()
+	}
+	return nil
+}
+
+func TestNewProcessorStore(t *testing.T) {
+	tests := []struct {
+		name      string
+		processor *channelProcessor
+		stopAfter int
+		wantItems int
+		wantErrs  int
+	}{
+		{"yields items", &channelProcessor{commits: 10}, -1, 10, 0},
+		{"yields process errors", &channelProcessor{processErr: errors.New("compare failed")}, -1, 0, 1},
+		{"drains after the consumer stops", &channelProcessor{commits: 10}, 2, 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := NewProcessorStore(tt.processor).WithConfig(&model.Config{})
+
+			items, errs := 0, 0
+			for _, err := range store.Changes(t.Context(), "v1", "v2") {
+				if err != nil {
+					errs++
+				} else {
+					items++
+				}
+				if items == tt.stopAfter {
+					break
+				}
+			}
+
+			assert.Equal(t, int32(tt.processor.commits), tt.processor.exited.Load(), "every sending goroutine has exited")
+			assert.Equal(t, tt.wantItems, items)
+			assert.Equal(t, tt.wantErrs, errs)
+		})
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// ResponseError is returned when a REST API responds with a non-success status code
+type ResponseError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// restClient performs JSON requests against a REST API for stores which aren't backed by a dedicated client library
+type restClient struct {
+	baseURL *url.URL
+	client  *http.Client
+	header  http.Header
+}
+
+// newRestClient creates a restClient for requests relative to baseURL, applying header (e.g. authorization) to every request
+func newRestClient(baseURL string, client *http.Client, header http.Header) (*restClient, error) {
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	if header == nil {
+		header = http.Header{}
+	}
+	return &restClient{baseURL: u, client: client, header: header}, nil
+}
+
+// get requests the already-escaped path relative to the base URL, decoding the JSON response body into v
+func (c *restClient) get(ctx context.Context, path string, query url.Values, v any) (*http.Response, error) {
+	u, err := c.baseURL.Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(query) > 0 {
+		u.RawQuery = query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, values := range c.header {
+		for _, value := range values {
+			req.Header.Add(k, value)
+		}
+	}
+	req.Header.Set("Accept", "application/json")
+
+	log.WithFields(log.Fields{"url": u.String()}).Debug("requesting")
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return resp, &ResponseError{Method: req.Method, URL: u.String(), StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	}
+
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			return resp, err
+		}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newAPIServer is a stand-in for a provider's REST API, serving routes (ServeMux patterns) to requests which must
+// carry authorization in the header named by header. Unrouted requests are answered with 404.
+func newAPIServer(t *testing.T, header string, authorization string, routes map[string]http.HandlerFunc) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	for pattern, handler := range routes {
+		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, authorization, r.Header.Get(header), "%s %s", r.Method, r.URL)
+			handler(w, r)
+		})
+	}
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// respond creates a handler writing body as the response
+func respond(body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func Test_restClient_get(t *testing.T) {
+	server := newAPIServer(t, "Authorization", "token secret", map[string]http.HandlerFunc{
+		"/api/thing": func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "application/json", r.Header.Get("Accept"))
+			assert.Equal(t, "2", r.URL.Query().Get("page"))
+			_, _ = w.Write([]byte(`{"name": "widget"}`))
+		},
+		"/api/broken": func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "something went wrong", http.StatusInternalServerError)
+		},
+	})
+
+	header := http.Header{}
+	header.Set("Authorization", "token secret")
+	api, err := newRestClient(server.URL+"/api", server.Client(), header)
+	assert.NoError(t, err)
+
+	thing := new(struct {
+		Name string `json:"name"`
+	})
+	_, err = api.get(t.Context(), "thing", url.Values{"page": {"2"}}, thing)
+	assert.NoError(t, err)
+	assert.Equal(t, "widget", thing.Name)
+
+	_, err = api.get(t.Context(), "broken", nil, nil)
+	var responseError *ResponseError
+	if assert.ErrorAs(t, err, &responseError) {
+		assert.Equal(t, http.StatusInternalServerError, responseError.StatusCode)
+		assert.Equal(t, "something went wrong", responseError.Body)
+		assert.Equal(t, server.URL+"/api/broken", responseError.URL)
+	}
+}
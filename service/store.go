@@ -16,9 +16,14 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"iter"
+	"net/http"
 	"regexp"
+	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/google/go-github/v29/github"
 	log "github.com/sirupsen/logrus"
@@ -34,17 +39,92 @@ type Store interface {
 	WithConfig(config *model.Config) Store
 	// GetContextual returns the context wrapper associated with this store
 	GetContextual() *Contextual
-	// Process queries the store and converts commits to a ChangeItem before sending to the channel
-	Process(parentContext *context.Context, wg *sync.WaitGroup, ciChan chan *model.ChangeItem, from string, to string) error
+	// Changes queries the store for commits reachable from 'to' but not from 'from', yielding each as a ChangeItem.
+	// A commit which can't be converted yields a *CommitError and the sequence continues; any other error ends the sequence.
+	// Consumers may stop at any time, which cancels the store's outstanding work.
+	Changes(ctx context.Context, from string, to string) iter.Seq2[model.ChangeItem, error]
 }
 
-func applyPullPropertiesChangeItem(ci *model.ChangeItem) {
+// CommitError reports a commit which couldn't be converted to a ChangeItem, and was therefore omitted. Stores return it
+// when a pull request lookup fails, as omitting the commit is safer than leaking a change which exclusion rules may have removed.
+type CommitError struct {
+	SHA string
+	Err error
+}
+
+func (e *CommitError) Error() string {
+	return fmt.Sprintf("commit %s: %v", e.SHA, e.Err)
+}
+
+func (e *CommitError) Unwrap() error {
+	return e.Err
+}
+
+// warnTruncated reports a range holding more than maximum commits (total, when known). Stores keep the newest, as the
+// changelog needs those most, so the oldest are omitted.
+func warnTruncated(from string, to string, total int, maximum int) {
+	fields := log.Fields{"from": from, "to": to, "max": maximum}
+	if total > 0 {
+		fields["total"] = total
+	}
+	log.WithFields(fields).Warn("Range exceeds the maximum number of commits, so the oldest are omitted. Raise it via --max or max_commits.")
+}
+
+// keepNewest truncates commits to the maximum newest by date, whichever end of the list the API orders them from
+func keepNewest[T any](commits []T, maximum int, date func(commit T) time.Time) []T {
+	if len(commits) <= maximum {
+		return commits
+	}
+	if date(commits[0]).After(date(commits[len(commits)-1])) {
+		return commits[:maximum]
+	}
+	return commits[len(commits)-maximum:]
+}
+
+// BranchSource is implemented by stores which can determine a repository's default branch
+type BranchSource interface {
+	// DefaultBranch returns the name of the default branch, or a revision standing in for it (such as HEAD)
+	DefaultBranch(ctx context.Context) (string, error)
+}
+
+// RefSource is implemented by stores which can describe the revisions bounding a changelog
+type RefSource interface {
+	// Ref resolves revision to its commit, describing the branch or tag it names
+	Ref(ctx context.Context, revision string) (*model.RefInfo, error)
+}
+
+// urlBuilder creates links for the configured repository, or nil when the URLs config is invalid
+func urlBuilder(config *model.Config) *model.URLBuilder {
+	if config == nil {
+		return nil
+	}
+	urls, err := config.URLBuilder()
+	if err != nil {
+		log.WithFields(log.Fields{"error": err}).Warn("Unable to create links for commits and pull requests")
+		return nil
+	}
+	return urls
+}
+
+// applyPullPropertiesChangeItem marks ci as a pull request when its title references one (e.g. "(#123)"), returning the
+// referenced pull request number. The pull URL is created by urls, or derived from the commit URL when urls is nil.
+func applyPullPropertiesChangeItem(ci *model.ChangeItem, urls *model.URLBuilder) string {
 	re := regexp.MustCompile(`.+?#(\d+).+?`)
 	title := ci.Title()
 	match := re.FindStringSubmatch(title)
-	if match != nil && len(match) > 0 {
-		isPull := true
-		ci.IsPullRaw = &isPull
+	if len(match) == 0 {
+		return ""
+	}
+
+	isPull := true
+	number, _ := strconv.Atoi(match[1])
+	ci.IsPullRaw = &isPull
+	ci.PullNumberRaw = &number
+	ci.PullSourceRaw = model.PullFromTitle.Ptr()
+	if urls != nil {
+		pullURL := urls.PullURL(match[1])
+		ci.PullURLRaw = &pullURL
+	} else {
 		baseUrl := ci.CommitURL()
 		idx := strings.LastIndex(baseUrl, "commit")
 		if idx > 0 {
@@ -54,18 +134,125 @@ func applyPullPropertiesChangeItem(ci *model.ChangeItem) {
 			builder.WriteString(match[1])
 			result := builder.String()
 			ci.PullURLRaw = &result
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"commitURL": ci.CommitURL(),
+		"pullURL":   ci.PullURL(),
+		"isPull":    ci.IsPull(),
+	}).Debug("applyPullPropertiesChangeItem")
+
+	return match[1]
+}
 
-			log.WithFields(log.Fields{
-				"baseURL":   baseUrl,
-				"commitIdx": idx,
-				"pullURL":   ci.PullURL(),
-				"isPull":    ci.IsPull(),
-			}).Debug("applyPullPropertiesChangeItem")
+// resolvePullRequest identifies the pull request which introduced ci, applying its properties to ci and evaluating exclusion rules.
+// With resolve: pulls, the association API is queried for the commit first; a pull request reference in the commit title is
+// used otherwise, or when no associated pull request exists. A nil contextual (or client) limits resolution to the commit title.
+// An error is returned when the pull request couldn't be queried, as exclusion rules can't be evaluated.
+func resolvePullRequest(ci *model.ChangeItem, urls *model.URLBuilder, contextual *Contextual, parent *context.Context, c *model.Config) (*github.PullRequest, bool, error) {
+	online := contextual != nil && contextual.GetClient() != nil
+	if online && c.GetResolveType() == model.PullRequests {
+		pr, err := findAssociatedPullRequest(ci.CommitHash(), contextual, parent, c)
+		if err != nil {
+			return nil, false, err
 		}
+		if pr != nil {
+			isPull := true
+			pullURL := pr.GetHTMLURL()
+			if urls != nil {
+				pullURL = urls.PullURL(strconv.Itoa(pr.GetNumber()))
+			}
+			ci.IsPullRaw = &isPull
+			ci.PullURLRaw = &pullURL
+			ci.PullSourceRaw = model.PullFromAssociation.Ptr()
+			applyPullRequestDetails(ci, pr)
+			return pr, shouldExcludePullRequest(pr, c), nil
+		}
+	}
+
+	pullId := applyPullPropertiesChangeItem(ci, urls)
+	if !ci.IsPull() || !online {
+		return nil, false, nil
+	}
+
+	// ignoring error here is intentional. if the ID is not parseable (should never happen), just evaluate the rules.
+	// the API call to retrieve PR will then respond with 404 and exclude will be false.
+	id, _ := strconv.Atoi(pullId)
+	pr, exclude, err := shouldExcludeViaPullAttributes(id, contextual, parent, c)
+	if isStatus(err, http.StatusNotFound) {
+		if c.GetResolveType() == model.PullRequests {
+			// the title references an issue (e.g. "fix #12 off-by-one") rather than a pull request
+			ci.IsPullRaw = nil
+			ci.PullURLRaw = nil
+			ci.PullSourceRaw = nil
+			ci.PullNumberRaw = nil
+		}
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if pr != nil {
+		applyPullRequestDetails(ci, pr)
+	}
+	return pr, exclude, nil
+}
+
+// applyPullRequestDetails records the attributes of pr on ci, allowing commits to be collapsed by pull request
+func applyPullRequestDetails(ci *model.ChangeItem, pr *github.PullRequest) {
+	number := pr.GetNumber()
+	title := pr.GetTitle()
+	author := pr.GetUser().GetLogin()
+	authorURL := pr.GetUser().GetHTMLURL()
+	labels := make([]string, 0, len(pr.Labels))
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+
+	ci.PullNumberRaw = &number
+	ci.PullTitleRaw = &title
+	ci.PullAuthorRaw = &author
+	ci.PullAuthorURLRaw = &authorURL
+	ci.MergedAtRaw = pr.MergedAt
+	ci.MergeCommitRaw = pr.MergeCommitSHA
+	ci.LabelsRaw = labels
+}
+
+// findAssociatedPullRequest queries the pull requests associated with sha, returning the one which merged it. Commits
+// associated only with closed or open pull requests return nil, leaving the commit title to identify a pull request.
+// see https://docs.github.com/en/rest/commits/commits#list-pull-requests-associated-with-a-commit
+func findAssociatedPullRequest(sha string, contextual *Contextual, parent *context.Context, c *model.Config) (*github.PullRequest, error) {
+	client := contextual.GetClient()
+	timeout, cancel := contextual.CreateContext(parent)
+	defer cancel()
+
+	log.Debugf("Finding pull requests associated with %s", sha)
+	pulls, _, e := client.PullRequests.ListPullRequestsWithCommit(timeout, c.Owner, c.Repo, sha, nil)
+	if isStatus(e, http.StatusUnprocessableEntity) || isStatus(e, http.StatusNotFound) {
+		// commits which don't exist on the remote (e.g. unpushed local commits) have no associated pull requests
+		log.WithFields(log.Fields{"error": e, "sha": sha}).Debug("Commit not found when listing associated pull requests")
+		return nil, nil
+	}
+	if e != nil {
+		return nil, e
 	}
+
+	for _, pr := range pulls {
+		if pr.MergedAt != nil {
+			return pr, nil
+		}
+	}
+	return nil, nil
 }
 
-func shouldExcludeViaPullAttributes(pullId int, contextual *Contextual, parent *context.Context, c *model.Config) (*github.PullRequest, bool) {
+// isStatus determines whether err is a GitHub API error response with the status code
+func isStatus(err error, code int) bool {
+	var responseError *github.ErrorResponse
+	return errors.As(err, &responseError) && responseError.Response != nil && responseError.Response.StatusCode == code
+}
+
+func shouldExcludeViaPullAttributes(pullId int, contextual *Contextual, parent *context.Context, c *model.Config) (*github.PullRequest, bool, error) {
 	client := contextual.GetClient()
 	timeout, cancel := contextual.CreateContext(parent)
 	defer cancel()
@@ -73,15 +260,20 @@ func shouldExcludeViaPullAttributes(pullId int, contextual *Contextual, parent *
 	log.Debugf("Checking pull request %d", pullId)
 	pr, _, e := client.PullRequests.Get(timeout, (*c).Owner, (*c).Repo, pullId)
 	if e != nil || pr == nil {
-		return nil, false
+		return nil, false, e
 	}
+	return pr, shouldExcludePullRequest(pr, c), nil
+}
+
+// shouldExcludePullRequest evaluates the title and labels of pr against exclusion rules
+func shouldExcludePullRequest(pr *github.PullRequest, c *model.Config) bool {
 	if c.ShouldExcludeByText(pr.Title) {
-		return pr, true
+		return true
 	}
 	for _, label := range pr.Labels {
 		if c.ShouldExcludeByText(label.Name) {
-			return pr, true
+			return true
 		}
 	}
-	return pr, false
+	return false
 }
@@ -15,7 +15,15 @@
 package service
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
+	"time"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/assert"
 
 	"github.com/jimschubert/changelog/model"
 )
@@ -31,7 +39,10 @@ func Test_applyPullPropertiesChangeItem(t *testing.T) {
 		ci         *model.ChangeItem
 		expectURL  string
 		expectIsPR bool
+		urls       *model.URLBuilder
 	}
+	gitea, _ := model.NewURLBuilder("gitea", "https://git.example.com", "mirror", "cli", model.URLTemplates{})
+	custom, _ := model.NewURLBuilder("github", "https://review.example.com", "cli", "cli", model.URLTemplates{Pull: "{base}/r/{repo}/changes/{id}"})
 	tests := []struct {
 		name   string
 		fields fields
@@ -44,6 +55,7 @@ func Test_applyPullPropertiesChangeItem(t *testing.T) {
 				&model.ChangeItem{CommitMessageRaw: p("Some Commit Message with numbers 12345 and no # symbol preceding")},
 				"",
 				false,
+				nil,
 			},
 		},
 		{"should apply PR properties when formatted as 'Merge pull request #523 …'",
@@ -56,6 +68,7 @@ func Test_applyPullPropertiesChangeItem(t *testing.T) {
 				},
 				"https://github.com/cli/cli/pull/523",
 				true,
+				nil,
 			},
 		},
 		{"should apply PR properties when formatted as 'Some commit message (#1234)'",
@@ -68,12 +81,36 @@ func Test_applyPullPropertiesChangeItem(t *testing.T) {
 				},
 				"https://github.com/OpenAPITools/openapi-generator/pull/5540",
 				true,
+				nil,
+			},
+		},
+		{"should apply PR properties from url scheme",
+
+			fields{&model.Config{ResolveType: model.Commits.Ptr()}},
+			args{
+				&model.ChangeItem{
+					CommitMessageRaw: p("Merge pull request #523 from cli/title-body-web"),
+					CommitURLRaw:     p("https://github.com/cli/cli/commit/b5d0b7c640ad897f395a72074a0f4b31787e5826"),
+				},
+				"https://git.example.com/mirror/cli/pulls/523",
+				true,
+				gitea,
+			},
+		},
+		{"should apply PR properties from custom url template",
+
+			fields{&model.Config{ResolveType: model.Commits.Ptr()}},
+			args{
+				&model.ChangeItem{CommitMessageRaw: p("Fix Swift4 CI tests (#5540)")},
+				"https://review.example.com/r/cli/changes/5540",
+				true,
+				custom,
 			},
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			applyPullPropertiesChangeItem(tt.args.ci)
+			applyPullPropertiesChangeItem(tt.args.ci, tt.args.urls)
 			if gotURL := tt.args.ci.PullURL(); gotURL != tt.args.expectURL {
 				t.Errorf("applyPullPropertiesChangeItem() PullURL = %v, want = %v", gotURL, tt.args.expectURL)
 			}
@@ -83,3 +120,212 @@ func Test_applyPullPropertiesChangeItem(t *testing.T) {
 		})
 	}
 }
+
+// newPullServer is a stand-in for GitHub's pull request APIs, recording the paths requested
+func newPullServer(t *testing.T) (*github.Client, *[]string) {
+	t.Helper()
+	requested := make([]string, 0)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		switch r.URL.Path {
+		case "/repos/o/r/commits/aaaaaaaaaa/pulls":
+			_, _ = w.Write([]byte(`[
+				{"number": 6, "title": "Rebase merged change (closed)", "html_url": "https://github.com/o/r/pull/6"},
+				{"number": 7, "title": "Rebase merged change", "merged_at": "2026-01-02T10:00:00Z", "merge_commit_sha": "mmmmmmmmmm",
+				 "html_url": "https://github.com/o/r/pull/7", "user": {"login": "octocat"}, "labels": [{"name": "enhancement"}]}
+			]`))
+		case "/repos/o/r/commits/dddddddddd/pulls":
+			_, _ = w.Write([]byte(`[{"number": 8, "title": "Experiment", "merged_at": "2026-01-02T10:00:00Z", "labels": [{"name": "do not release"}]}]`))
+		case "/repos/o/r/commits/eeeeeeeeee/pulls":
+			_, _ = w.Write([]byte(`[{"number": 9, "title": "Abandoned attempt", "state": "closed", "html_url": "https://github.com/o/r/pull/9"}]`))
+		case "/repos/o/r/commits/bbbbbbbbbb/pulls", "/repos/o/r/commits/cccccccccc/pulls":
+			_, _ = w.Write([]byte(`[]`))
+		case "/repos/o/r/pulls/14":
+			http.Error(w, `{"message": "Server Error"}`, http.StatusInternalServerError)
+		case "/repos/o/r/pulls/13":
+			_, _ = w.Write([]byte(`{"number": 13, "title": "Add thing", "html_url": "https://github.com/o/r/pull/13"}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	return client, &requested
+}
+
+func Test_resolvePullRequest(t *testing.T) {
+	type want struct {
+		isPull    bool
+		pullURL   string
+		source    model.PullSource
+		exclude   bool
+		requested []string
+	}
+	tests := []struct {
+		name    string
+		resolve model.ResolveType
+		offline bool
+		sha     string
+		message string
+		want    want
+	}{
+		{"association finds a rebase merged pull request without a title reference",
+			model.PullRequests, false, "aaaaaaaaaa", "Rebase merged change",
+			want{true, "https://github.com/o/r/pull/7", model.PullFromAssociation, false, []string{"/repos/o/r/commits/aaaaaaaaaa/pulls"}},
+		},
+		{"association takes precedence over a title reference",
+			model.PullRequests, false, "aaaaaaaaaa", "Rebase merged change (#13)",
+			want{true, "https://github.com/o/r/pull/7", model.PullFromAssociation, false, []string{"/repos/o/r/commits/aaaaaaaaaa/pulls"}},
+		},
+		{"association evaluates pull request labels for exclusion",
+			model.PullRequests, false, "dddddddddd", "Experiment",
+			want{true, "https://github.com/o/r/pull/8", model.PullFromAssociation, true, []string{"/repos/o/r/commits/dddddddddd/pulls"}},
+		},
+		{"falls back to a title reference when no pull request is associated",
+			model.PullRequests, false, "cccccccccc", "Add thing (#13)",
+			want{true, "https://github.com/o/r/pull/13", model.PullFromTitle, false, []string{"/repos/o/r/commits/cccccccccc/pulls", "/repos/o/r/pulls/13"}},
+		},
+		{"falls back to a title reference when no associated pull request was merged",
+			model.PullRequests, false, "eeeeeeeeee", "Add thing (#13)",
+			want{true, "https://github.com/o/r/pull/13", model.PullFromTitle, false, []string{"/repos/o/r/commits/eeeeeeeeee/pulls", "/repos/o/r/pulls/13"}},
+		},
+		{"issue references in the title are not pull requests",
+			model.PullRequests, false, "bbbbbbbbbb", "fix #12 off-by-one",
+			want{false, "", 0, false, []string{"/repos/o/r/commits/bbbbbbbbbb/pulls", "/repos/o/r/pulls/12"}},
+		},
+		{"commits mode only considers the title",
+			model.Commits, false, "aaaaaaaaaa", "fix #12 off-by-one",
+			want{true, "https://github.com/o/r/pull/12", model.PullFromTitle, false, []string{"/repos/o/r/pulls/12"}},
+		},
+		{"offline only considers the title",
+			model.PullRequests, true, "aaaaaaaaaa", "Add thing (#13)",
+			want{true, "https://github.com/o/r/pull/13", model.PullFromTitle, false, []string{}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requested := newPullServer(t)
+			contextual := newContextual(client)
+			if tt.offline {
+				contextual = nil
+			}
+			config := &model.Config{Owner: "o", Repo: "r", ResolveType: tt.resolve.Ptr(), Exclude: []string{"do not release"}}
+			ci := &model.ChangeItem{CommitHashRaw: &tt.sha, CommitMessageRaw: &tt.message}
+			ctx := context.Background()
+
+			_, exclude, err := resolvePullRequest(ci, urlBuilder(config), contextual, &ctx, config)
+			assert.NoError(t, err)
+
+			assert.Equal(t, tt.want.exclude, exclude)
+			assert.Equal(t, tt.want.isPull, ci.IsPull())
+			assert.Equal(t, tt.want.pullURL, ci.PullURL())
+			assert.Equal(t, tt.want.source, ci.PullSource())
+			assert.Equal(t, tt.want.requested, *requested)
+		})
+	}
+}
+
+func Test_resolvePullRequest_details(t *testing.T) {
+	client, _ := newPullServer(t)
+	config := &model.Config{Owner: "o", Repo: "r", ResolveType: model.PullRequests.Ptr()}
+	sha := "aaaaaaaaaa"
+	message := "Rebase merged change"
+	ci := &model.ChangeItem{CommitHashRaw: &sha, CommitMessageRaw: &message}
+	ctx := context.Background()
+
+	_, exclude, err := resolvePullRequest(ci, urlBuilder(config), newContextual(client), &ctx, config)
+	assert.NoError(t, err)
+
+	assert.False(t, exclude)
+	assert.Equal(t, 7, ci.PullNumber())
+	assert.Equal(t, "Rebase merged change", ci.PullTitle())
+	assert.Equal(t, "octocat", ci.PullAuthor())
+	assert.Equal(t, "2026-01-02T10:00:00Z", ci.MergedAt().Format("2006-01-02T15:04:05Z07:00"))
+	assert.Equal(t, "mmmmmmmmmm", ci.MergeCommit())
+	assert.Equal(t, []string{"enhancement"}, ci.Labels())
+}
+
+func Test_resolvePullRequest_error(t *testing.T) {
+	client, _ := newPullServer(t)
+	config := &model.Config{Owner: "o", Repo: "r", ResolveType: model.PullRequests.Ptr(), Exclude: []string{"do not release"}}
+	sha := "cccccccccc"
+	message := "Add other thing (#14)"
+	ci := &model.ChangeItem{CommitHashRaw: &sha, CommitMessageRaw: &message}
+	ctx := context.Background()
+
+	_, exclude, err := resolvePullRequest(ci, urlBuilder(config), newContextual(client), &ctx, config)
+	assert.Error(t, err, "failures to query a pull request must not skip exclusion rules")
+	assert.False(t, exclude)
+}
+
+func Test_DefaultBranch(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"default_branch": "main"}`))
+	})
+	mux.HandleFunc("/api/v4/projects/o%2Fr", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"default_branch": "trunk"}`))
+	})
+	mux.HandleFunc("/api/v1/repos/o/r", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"default_branch": "develop"}`))
+	})
+	mux.HandleFunc("/rest/api/1.0/projects/o/repos/r/branches/default", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "refs/heads/release", "displayId": "release"}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	gitlab, _ := NewGitLabService(server.URL, "", nil)
+	gitea, _ := NewGiteaService(server.URL, "", nil)
+	bitbucket, _ := NewBitbucketServerService(server.URL, "", nil)
+
+	tests := []struct {
+		name  string
+		store Store
+		want  string
+	}{
+		{"github", NewGitHubService().WithClient(client), "main"},
+		{"gitlab", gitlab, "trunk"},
+		{"gitea", gitea, "develop"},
+		{"bitbucket server", bitbucket, "release"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := tt.store.WithConfig(&model.Config{Owner: "o", Repo: "r"}).(BranchSource)
+			got, err := source.DefaultBranch(t.Context())
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_keepNewest(t *testing.T) {
+	day := func(d int) time.Time {
+		return time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC)
+	}
+	identity := func(commit time.Time) time.Time {
+		return commit
+	}
+
+	tests := []struct {
+		name    string
+		commits []time.Time
+		maximum int
+		want    []time.Time
+	}{
+		{"within maximum", []time.Time{day(1), day(2)}, 2, []time.Time{day(1), day(2)}},
+		{"ordered from the oldest", []time.Time{day(1), day(2), day(3), day(4)}, 2, []time.Time{day(3), day(4)}},
+		{"ordered from the newest", []time.Time{day(4), day(3), day(2), day(1)}, 2, []time.Time{day(4), day(3)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, keepNewest(tt.commits, tt.maximum, identity))
+		})
+	}
+}
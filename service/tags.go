@@ -0,0 +1,101 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+// Tag is a named reference to a commit
+type Tag struct {
+	Name string
+	SHA  string
+}
+
+// TagSource is implemented by stores which can list a repository's tags, allowing the previous release to be detected.
+// These stores also accept an empty from in Changes, yielding every commit reachable from to (such as the first release).
+type TagSource interface {
+	// Tags lists the repository's tags, with annotated tags peeled to the commit they reference
+	Tags(ctx context.Context) ([]Tag, error)
+	// Precedes determines whether the commit at revision is an ancestor of (reachable from, and not the same commit as) to
+	Precedes(ctx context.Context, revision string, to string) (bool, error)
+}
+
+// ReleaseTag is a tag whose name is a semantic version
+type ReleaseTag struct {
+	Tag
+	Version *model.Version
+}
+
+// ReleaseTags filters tags to those considered releases by config (tag_prefix, tag_pattern and skip_prerelease),
+// ordered from the highest version to the lowest
+func ReleaseTags(tags []Tag, config *model.Config) ([]ReleaseTag, error) {
+	pattern, err := config.GetTagPattern()
+	if err != nil {
+		return nil, err
+	}
+	prefix := config.GetTagPrefix()
+
+	releases := make([]ReleaseTag, 0, len(tags))
+	for _, tag := range tags {
+		name, found := strings.CutPrefix(tag.Name, prefix)
+		if !found || (pattern != nil && !pattern.MatchString(tag.Name)) {
+			continue
+		}
+		version, err := model.ParseVersion(name)
+		if err != nil || (version.IsPrerelease() && config.GetSkipPrerelease()) {
+			continue
+		}
+		releases = append(releases, ReleaseTag{Tag: tag, Version: version})
+	}
+
+	slices.SortStableFunc(releases, func(a, b ReleaseTag) int {
+		if c := b.Version.Compare(a.Version); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return releases, nil
+}
+
+// PreviousRelease finds the highest versioned release tag which precedes to, returning an empty string when there is none
+func PreviousRelease(ctx context.Context, source TagSource, config *model.Config, to string) (string, error) {
+	tags, err := source.Tags(ctx)
+	if err != nil {
+		return "", err
+	}
+	releases, err := ReleaseTags(tags, config)
+	if err != nil {
+		return "", err
+	}
+
+	for _, release := range releases {
+		precedes, err := source.Precedes(ctx, release.Name, to)
+		if err != nil {
+			return "", err
+		}
+		if precedes {
+			return release.Name, nil
+		}
+		log.WithFields(log.Fields{"tag": release.Name, "to": to}).Debug("release tag doesn't precede 'to'")
+	}
+	return "", nil
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-github/v29/github"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/jimschubert/changelog/model"
+)
+
+func TestReleaseTags(t *testing.T) {
+	p := func(s string) *string {
+		return &s
+	}
+	skip := true
+	tags := []Tag{
+		{Name: "v1.2.0"}, {Name: "v1.10.0"}, {Name: "1.9.0"}, {Name: "v2.0.0-rc.1"}, {Name: "nightly"},
+		{Name: "api/v3.0.0"}, {Name: "api/v3.1.0-beta.1"}, {Name: "api/2.5.0"},
+	}
+	tests := []struct {
+		name    string
+		config  *model.Config
+		want    []string
+		wantErr bool
+	}{
+		{"semantic versions ordered by precedence", &model.Config{}, []string{"v2.0.0-rc.1", "v1.10.0", "1.9.0", "v1.2.0"}, false},
+		{"prereleases skipped", &model.Config{SkipPrerelease: &skip}, []string{"v1.10.0", "1.9.0", "v1.2.0"}, false},
+		{"prefix", &model.Config{TagPrefix: p("api/")}, []string{"api/v3.1.0-beta.1", "api/v3.0.0", "api/2.5.0"}, false},
+		{"pattern", &model.Config{TagPattern: p(`^v1\.`)}, []string{"v1.10.0", "v1.2.0"}, false},
+		{"invalid pattern", &model.Config{TagPattern: p(`v(`)}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			releases, err := ReleaseTags(tags, tt.config)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			got := make([]string, 0, len(releases))
+			for _, release := range releases {
+				got = append(got, release.Name)
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPreviousRelease_local(t *testing.T) {
+	// A - B - C - D (master)
+	//      \
+	//       X        (hotfix, tagged v1.1.1)
+	r := newTestRepo(t)
+	a := r.commit("A")
+	b := r.commit("B", a)
+	c := r.commit("C", b)
+	d := r.commit("D", c)
+	x := r.commit("X", b)
+	r.branch("master", d)
+	r.tag("v1.0.0", a, "")
+	r.tag("v1.1.0", b, "Release 1.1.0")
+	r.tag("v1.1.1", x, "")
+	r.tag("v1.2.0-rc.1", c, "")
+	r.tag("v1.2.0", d, "")
+
+	skip := true
+	tests := []struct {
+		name   string
+		config *model.Config
+		to     string
+		want   string
+	}{
+		{"nearest release preceding to", &model.Config{}, "master~1", "v1.1.0"},
+		{"tag at to isn't its own predecessor", &model.Config{}, "v1.2.0", "v1.2.0-rc.1"},
+		{"prereleases skipped", &model.Config{SkipPrerelease: &skip}, "v1.2.0", "v1.1.0"},
+		{"no release precedes to", &model.Config{}, "v1.0.0", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Path = &r.dir
+			store := NewLocalGitService().WithConfig(tt.config).(TagSource)
+
+			got, err := PreviousRelease(t.Context(), store, tt.config, tt.to)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPreviousRelease_github(t *testing.T) {
+	tags := []string{"v0.9.0", "v1.0.0", "v1.1.0", "v2.0.0"}
+	// v2.0.0 was released from a branch which main doesn't contain
+	status := map[string]string{"v2.0.0": "diverged", "v1.1.0": "ahead", "v1.0.0": "ahead", "v0.9.0": "ahead"}
+	compared := make([]string, 0)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/tags", func(w http.ResponseWriter, r *http.Request) {
+		// one tag per page, so pagination is exercised
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		page = max(page, 1)
+		if page < len(tags) {
+			w.Header().Set("Link", fmt.Sprintf(`<%s?page=%d>; rel="next"`, r.URL.Path, page+1))
+		}
+		_ = json.NewEncoder(w).Encode([]github.RepositoryTag{{Name: github.String(tags[page-1])}})
+	})
+	mux.HandleFunc("/repos/o/r/compare/", func(w http.ResponseWriter, r *http.Request) {
+		base, _ := url.PathUnescape(r.URL.EscapedPath()[len("/repos/o/r/compare/"):])
+		base = base[:len(base)-len("...main")]
+		compared = append(compared, base)
+		_ = json.NewEncoder(w).Encode(github.CommitsComparison{Status: github.String(status[base])})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(server.URL + "/")
+	config := &model.Config{Owner: "o", Repo: "r"}
+	store := NewGitHubService().WithClient(client).WithConfig(config).(TagSource)
+
+	got, err := PreviousRelease(t.Context(), store, config, "main")
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.1.0", got)
+	assert.Equal(t, []string{"v2.0.0", "v1.1.0"}, compared, "candidates are compared from the highest version until one precedes to")
+}
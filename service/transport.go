@@ -0,0 +1,326 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// secondaryRateLimitWait is the minimum wait after a secondary rate limit which doesn't define Retry-After
+// see https://docs.github.com/en/rest/using-the-rest-api/best-practices-for-using-the-rest-api#handle-rate-limit-errors-appropriately
+const secondaryRateLimitWait = time.Minute
+
+// RateLimitError is returned when an API's rate limit is exhausted and won't reset within the transport's MaxWait
+type RateLimitError struct {
+	URL   string
+	Reset time.Time
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("rate limit exhausted requesting %s, resets at %s", e.URL, e.Reset.Format(time.RFC3339))
+}
+
+// Is allows errors.Is(err, ErrRateLimited)
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// RetryTransport is an http.RoundTripper which honors rate limits (X-RateLimit-* and Retry-After headers) and
+// retries transient failures with jittered exponential backoff
+type RetryTransport struct {
+	// Base performs requests; defaults to http.DefaultTransport
+	Base http.RoundTripper
+
+	// MaxRetries is the number of times a request is retried after a transient failure or rate limit
+	MaxRetries int
+
+	// MinBackoff is the delay before the first retry of a transient failure, doubling for each subsequent retry
+	MinBackoff time.Duration
+
+	// MaxBackoff caps the delay between retries of transient failures
+	MaxBackoff time.Duration
+
+	// MaxWait is the longest the transport waits for a rate limit to reset before failing with a RateLimitError
+	MaxWait time.Duration
+
+	// Timeout bounds each attempt, including reading its response body. Waits between attempts are bounded only by
+	// the request's context and MaxWait. An attempt which times out isn't retried. Zero means no timeout.
+	Timeout time.Duration
+
+	// Now is the time source for rate limit resets; defaults to time.Now
+	Now func() time.Time
+
+	mu      sync.Mutex
+	resetAt time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// NewRetryTransport creates a RetryTransport wrapping base with default retry and rate limit settings
+func NewRetryTransport(base http.RoundTripper) *RetryTransport {
+	return &RetryTransport{
+		Base:       base,
+		MaxRetries: 4,
+		MinBackoff: time.Second,
+		MaxBackoff: 30 * time.Second,
+		MaxWait:    2 * time.Minute,
+	}
+}
+
+// RoundTrip performs req, waiting out rate limits and retrying transient failures
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.awaitBudget(req); err != nil {
+			return nil, err
+		}
+
+		attemptReq, cancel, err := t.attemptRequest(req, attempt)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := base.RoundTrip(attemptReq)
+		wait, err := t.evaluate(req, resp, err, attempt)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if wait <= 0 {
+			if resp.Body != nil {
+				resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			} else {
+				cancel()
+			}
+			return resp, nil
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			_ = resp.Body.Close()
+		}
+		cancel()
+
+		log.WithFields(log.Fields{
+			"url":     req.URL.String(),
+			"attempt": attempt + 1,
+			"wait":    wait.String(),
+		}).Warn("Retrying request")
+
+		if err := t.doSleep(req.Context(), wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// attemptRequest prepares req for an attempt, bounded by Timeout and replaying the body of retried requests. The returned
+// context.CancelFunc must be called once the attempt's response is no longer read.
+func (t *RetryTransport) attemptRequest(req *http.Request, attempt int) (*http.Request, context.CancelFunc, error) {
+	ctx, cancel := req.Context(), context.CancelFunc(func() {})
+	if t.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, t.Timeout)
+	}
+
+	attemptReq := req.WithContext(ctx)
+	if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+		if req.GetBody == nil {
+			cancel()
+			return nil, nil, fmt.Errorf("unable to retry %s %s: request body can't be replayed", req.Method, req.URL)
+		}
+		body, err := req.GetBody()
+		if err != nil {
+			cancel()
+			return nil, nil, err
+		}
+		attemptReq = req.Clone(ctx)
+		attemptReq.Body = body
+	}
+	return attemptReq, cancel, nil
+}
+
+// cancelOnClose releases an attempt's context once its response body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// evaluate determines how long to wait before retrying; a non-positive wait means resp and err are final
+func (t *RetryTransport) evaluate(req *http.Request, resp *http.Response, err error, attempt int) (time.Duration, error) {
+	if err != nil {
+		if req.Context().Err() != nil || errors.Is(err, context.DeadlineExceeded) || attempt >= t.MaxRetries {
+			return 0, err
+		}
+		return t.backoff(attempt), nil
+	}
+
+	t.observe(resp)
+
+	if reset, limited := t.rateLimitReset(resp); limited {
+		wait := reset.Sub(t.clock())
+		if wait > t.MaxWait || attempt >= t.MaxRetries {
+			_ = resp.Body.Close()
+			return 0, &RateLimitError{URL: req.URL.String(), Reset: reset}
+		}
+		return max(wait, time.Millisecond), nil
+	}
+
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		if attempt >= t.MaxRetries {
+			return 0, nil
+		}
+		return t.backoff(attempt), nil
+	default:
+		return 0, nil
+	}
+}
+
+// rateLimitReset determines whether resp was rejected by a primary or secondary rate limit, and when a retry may succeed
+func (t *RetryTransport) rateLimitReset(resp *http.Response) (time.Time, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return time.Time{}, false
+	}
+
+	now := t.clock()
+	if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return now.Add(time.Duration(seconds) * time.Second), true
+		}
+		if at, err := http.ParseTime(retryAfter); err == nil {
+			return at, true
+		}
+	}
+
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, ok := parseReset(resp.Header); ok {
+			return reset, true
+		}
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return now.Add(secondaryRateLimitWait), true
+	}
+
+	// a 403 is only a secondary rate limit when the body says so; otherwise it's a permissions error
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	_ = resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	if strings.Contains(strings.ToLower(string(body)), "rate limit") {
+		return now.Add(secondaryRateLimitWait), true
+	}
+
+	return time.Time{}, false
+}
+
+// observe records an exhausted rate limit budget, so subsequent requests wait for the reset rather than being rejected
+func (t *RetryTransport) observe(resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if remaining != "0" {
+		t.resetAt = time.Time{}
+		return
+	}
+	if reset, ok := parseReset(resp.Header); ok {
+		t.resetAt = reset
+	}
+}
+
+// awaitBudget waits for an exhausted rate limit to reset, failing when the reset is beyond MaxWait
+func (t *RetryTransport) awaitBudget(req *http.Request) error {
+	t.mu.Lock()
+	reset := t.resetAt
+	t.mu.Unlock()
+
+	wait := reset.Sub(t.clock())
+	if wait <= 0 {
+		return nil
+	}
+	if wait > t.MaxWait {
+		return &RateLimitError{URL: req.URL.String(), Reset: reset}
+	}
+
+	log.WithFields(log.Fields{"url": req.URL.String(), "wait": wait.String()}).Warn("Rate limit exhausted, waiting for reset")
+	return t.doSleep(req.Context(), wait)
+}
+
+// backoff is the jittered delay before retry number attempt+1 of a transient failure
+func (t *RetryTransport) backoff(attempt int) time.Duration {
+	d := t.MinBackoff << attempt
+	if d <= 0 || d > t.MaxBackoff {
+		d = t.MaxBackoff
+	}
+	half := d / 2
+	if half <= 0 {
+		return max(d, time.Millisecond)
+	}
+	return half + rand.N(half)
+}
+
+func (t *RetryTransport) clock() time.Time {
+	if t.Now != nil {
+		return t.Now()
+	}
+	return time.Now()
+}
+
+func (t *RetryTransport) doSleep(ctx context.Context, d time.Duration) error {
+	if t.sleep != nil {
+		return t.sleep(ctx, d)
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseReset reads the epoch seconds of X-RateLimit-Reset
+func parseReset(header http.Header) (time.Time, bool) {
+	epoch, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(epoch, 0), true
+}
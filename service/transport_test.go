@@ -0,0 +1,218 @@
+// Copyright 2026 Jim Schubert
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// scriptedResponse creates a response for the fake transport; a zero status represents a network failure
+type scriptedResponse struct {
+	status int
+	header map[string]string
+	body   string
+}
+
+// newScriptedTransport creates a RetryTransport replaying responses in order, with a fake clock advanced by sleeps
+func newScriptedTransport(t *testing.T, responses ...scriptedResponse) (*RetryTransport, *[]time.Duration, *[]string) {
+	t.Helper()
+	now := time.Unix(1767348000, 0)
+	sleeps := make([]time.Duration, 0)
+	bodies := make([]string, 0)
+	calls := 0
+
+	transport := NewRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.Body != nil {
+			b, _ := io.ReadAll(req.Body)
+			bodies = append(bodies, string(b))
+		}
+		if calls >= len(responses) {
+			t.Fatalf("unexpected request %d", calls+1)
+		}
+		r := responses[calls]
+		calls++
+		if r.status == 0 {
+			return nil, errors.New("connection reset by peer")
+		}
+		header := http.Header{}
+		for k, v := range r.header {
+			header.Set(k, v)
+		}
+		return &http.Response{StatusCode: r.status, Header: header, Body: io.NopCloser(strings.NewReader(r.body)), Request: req}, nil
+	}))
+	transport.Now = func() time.Time { return now }
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		sleeps = append(sleeps, d)
+		now = now.Add(d)
+		return nil
+	}
+	return transport, &sleeps, &bodies
+}
+
+func TestRetryTransport_RoundTrip(t *testing.T) {
+	reset := func(seconds int) string { return strconv.FormatInt(1767348000+int64(seconds), 10) }
+	ok := scriptedResponse{status: http.StatusOK, body: "ok"}
+	tests := []struct {
+		name       string
+		responses  []scriptedResponse
+		wantStatus int
+		wantSleeps []time.Duration
+		wantErr    bool
+	}{
+		{"success is returned immediately",
+			[]scriptedResponse{ok}, http.StatusOK, []time.Duration{}, false},
+		{"retries a bad gateway",
+			[]scriptedResponse{{status: http.StatusBadGateway}, ok}, http.StatusOK, nil, false},
+		{"retries a network failure",
+			[]scriptedResponse{{}, ok}, http.StatusOK, nil, false},
+		{"returns the last transient failure once retries are exhausted",
+			[]scriptedResponse{{status: 503}, {status: 503}, {status: 503}, {status: 503}, {status: 503}}, http.StatusServiceUnavailable, nil, false},
+		{"waits for Retry-After",
+			[]scriptedResponse{{status: http.StatusForbidden, header: map[string]string{"Retry-After": "5"}}, ok},
+			http.StatusOK, []time.Duration{5 * time.Second}, false},
+		{"waits for a primary rate limit reset within MaxWait",
+			[]scriptedResponse{{status: http.StatusForbidden, header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(30)}}, ok},
+			http.StatusOK, []time.Duration{30 * time.Second}, false},
+		{"waits a minute for a secondary rate limit without Retry-After",
+			[]scriptedResponse{{status: http.StatusForbidden, body: `{"message": "You have exceeded a secondary rate limit."}`}, ok},
+			http.StatusOK, []time.Duration{time.Minute}, false},
+		{"fails loudly when the rate limit resets beyond MaxWait",
+			[]scriptedResponse{{status: http.StatusForbidden, header: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": reset(3600)}}},
+			0, []time.Duration{}, true},
+		{"returns permission errors unchanged",
+			[]scriptedResponse{{status: http.StatusForbidden, body: `{"message": "Resource not accessible by integration"}`}},
+			http.StatusForbidden, []time.Duration{}, false},
+		{"returns client errors unchanged",
+			[]scriptedResponse{{status: http.StatusNotFound}}, http.StatusNotFound, []time.Duration{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport, sleeps, _ := newScriptedTransport(t, tt.responses...)
+			req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/pulls/1", nil)
+
+			resp, err := transport.RoundTrip(req)
+			if tt.wantErr {
+				var rateLimitError *RateLimitError
+				assert.ErrorAs(t, err, &rateLimitError)
+				assert.Nil(t, resp)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantStatus, resp.StatusCode)
+				body, _ := io.ReadAll(resp.Body)
+				assert.Equal(t, tt.responses[len(tt.responses)-1].body, string(body), "the final response body remains readable")
+			}
+
+			if tt.wantSleeps != nil {
+				assert.Equal(t, tt.wantSleeps, *sleeps)
+			} else {
+				assert.Len(t, *sleeps, len(tt.responses)-1)
+				for _, d := range *sleeps {
+					assert.LessOrEqual(t, d, transport.MaxBackoff)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryTransport_exhaustedBudget(t *testing.T) {
+	header := func(remaining string, resetIn int) map[string]string {
+		return map[string]string{"X-RateLimit-Remaining": remaining, "X-RateLimit-Reset": strconv.FormatInt(1767348000+int64(resetIn), 10)}
+	}
+	transport, sleeps, _ := newScriptedTransport(t,
+		scriptedResponse{status: http.StatusOK, header: header("0", 30)},
+		scriptedResponse{status: http.StatusOK, header: header("4999", 3600)},
+		scriptedResponse{status: http.StatusOK, header: header("0", 3600)},
+	)
+
+	for range 3 {
+		req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/pulls/1", nil)
+		resp, err := transport.RoundTrip(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+	assert.Equal(t, []time.Duration{30 * time.Second}, *sleeps, "requests after an exhausted budget wait for the reset")
+
+	req, _ := http.NewRequest(http.MethodGet, "https://api.github.com/repos/o/r/pulls/1", nil)
+	_, err := transport.RoundTrip(req)
+	var rateLimitError *RateLimitError
+	assert.ErrorAs(t, err, &rateLimitError, "requests fail without a round trip when the reset is beyond MaxWait")
+	assert.ErrorIs(t, err, ErrRateLimited)
+}
+
+func TestRetryTransport_replaysBody(t *testing.T) {
+	transport, _, bodies := newScriptedTransport(t, scriptedResponse{status: http.StatusBadGateway}, scriptedResponse{status: http.StatusOK})
+	req, _ := http.NewRequest(http.MethodPost, "https://api.github.com/graphql", strings.NewReader(`{"query": "{}"}`))
+
+	resp, err := transport.RoundTrip(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, []string{`{"query": "{}"}`, `{"query": "{}"}`}, *bodies)
+}
+
+func TestRetryTransport_timeout(t *testing.T) {
+	limited := true
+	transport := NewRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		status := http.StatusOK
+		if limited {
+			status, limited = http.StatusTooManyRequests, false
+		}
+		return &http.Response{StatusCode: status, Header: http.Header{"Retry-After": {"15"}}, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	}))
+	transport.Timeout = 50 * time.Millisecond
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		// waiting for the rate limit outlasts the timeout of any single attempt
+		time.Sleep(2 * transport.Timeout)
+		return ctx.Err()
+	}
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	resp, err := transport.RoundTrip(req)
+	if assert.NoError(t, err, "waits for rate limits aren't bounded by the timeout") {
+		body, err := io.ReadAll(resp.Body)
+		assert.NoError(t, err, "the body is readable within the attempt's timeout")
+		assert.Equal(t, "ok", string(body))
+		assert.NoError(t, resp.Body.Close())
+	}
+}
+
+func TestRetryTransport_attemptTimeout(t *testing.T) {
+	attempts := 0
+	transport := NewRetryTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}))
+	transport.Timeout = 50 * time.Millisecond
+
+	req, _ := http.NewRequestWithContext(t.Context(), http.MethodGet, "https://api.github.com/repos/o/r", nil)
+	_, err := transport.RoundTrip(req)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, attempts, "an attempt which times out isn't retried")
+}